@@ -1,12 +1,16 @@
 package images
 
 import (
+	"fmt"
 	"image"
 	"image/color"
+	_ "image/gif"
+	_ "image/jpeg"
 	"image/png"
 	"io"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"subman/internal/models"
 )
@@ -83,26 +87,14 @@ func DeleteImage(filename string) error {
 }
 
 // GenerateDefaultCategoryIcon creates a simple colored square icon for a category
-func GenerateDefaultCategoryIcon(category models.Category) (image.Image, error) {
+func GenerateDefaultCategoryIcon(category models.CategoryDef) (image.Image, error) {
 	// 128x128 square
 	width, height := 128, 128
 	img := image.NewRGBA(image.Rect(0, 0, width, height))
 
-	// Category colors
-	categoryColors := map[models.Category]color.RGBA{
-		models.Streaming:  {R: 229, G: 57, B: 53, A: 255},   // Red
-		models.Software:   {R: 52, G: 152, B: 219, A: 255},  // Blue
-		models.Utilities:  {R: 46, G: 204, B: 113, A: 255},  // Green
-		models.Gaming:     {R: 155, G: 89, B: 182, A: 255},  // Purple
-		models.News:       {R: 241, G: 196, B: 15, A: 255},  // Yellow
-		models.Education:  {R: 26, G: 188, B: 156, A: 255},  // Teal
-		models.Creator:    {R: 230, G: 126, B: 34, A: 255},  // Orange
-		models.Other:      {R: 149, G: 165, B: 166, A: 255}, // Gray
-	}
-
-	bgColor, exists := categoryColors[category]
-	if !exists {
-		bgColor = categoryColors[models.Other]
+	bgColor, err := ParseHexColor(category.Color)
+	if err != nil {
+		bgColor, _ = ParseHexColor(models.DefaultCategoryColor)
 	}
 
 	// Fill with solid color
@@ -115,52 +107,84 @@ func GenerateDefaultCategoryIcon(category models.Category) (image.Image, error)
 	return img, nil
 }
 
+// ParseHexColor parses a "#rrggbb" colour
+func ParseHexColor(hex string) (color.RGBA, error) {
+	c := color.RGBA{A: 255}
+	if len(hex) != 7 || !strings.HasPrefix(hex, "#") {
+		return c, fmt.Errorf("invalid colour %q, expected #rrggbb", hex)
+	}
+	if _, err := fmt.Sscanf(hex[1:], "%02x%02x%02x", &c.R, &c.G, &c.B); err != nil {
+		return c, fmt.Errorf("invalid colour %q, expected #rrggbb", hex)
+	}
+	return c, nil
+}
+
 // EnsureDefaultCategoryIcons creates default icon files for all categories if they don't exist
-func EnsureDefaultCategoryIcons() error {
+func EnsureDefaultCategoryIcons(categories []models.CategoryDef) error {
 	imagesDir, err := GetImagesDir()
 	if err != nil {
 		return err
 	}
 
-	categories := []models.Category{
-		models.Streaming,
-		models.Software,
-		models.Utilities,
-		models.Gaming,
-		models.News,
-		models.Education,
-		models.Creator,
-		models.Other,
-	}
-
 	for _, cat := range categories {
-		filename := "default_" + string(cat) + ".png"
-		imagePath := filepath.Join(imagesDir, filename)
+		imagePath := filepath.Join(imagesDir, GetDefaultImageForCategory(cat.ID))
 
 		// Skip if already exists
 		if _, err := os.Stat(imagePath); err == nil {
 			continue
 		}
 
-		// Generate and save
-		img, err := GenerateDefaultCategoryIcon(cat)
-		if err != nil {
+		if err := WriteCategoryIcon(cat); err != nil {
 			return err
 		}
+	}
 
-		file, err := os.Create(imagePath)
-		if err != nil {
-			return err
-		}
+	return nil
+}
 
-		err = png.Encode(file, img)
-		file.Close()
-		if err != nil {
-			return err
-		}
+// WriteCategoryIcon (re)generates the default icon of a category from its colour
+func WriteCategoryIcon(category models.CategoryDef) error {
+	img, err := GenerateDefaultCategoryIcon(category)
+	if err != nil {
+		return err
 	}
+	return writePNG(GetDefaultImageForCategory(category.ID), img)
+}
 
-	return nil
+// SetCategoryIcon replaces the default icon of a category with an image file
+// The image is stored as PNG so every subscription using the default picks it up
+func SetCategoryIcon(category models.CategoryDef, sourcePath string) error {
+	src, err := os.Open(sourcePath)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	img, _, err := image.Decode(src)
+	if err != nil {
+		return fmt.Errorf("unsupported image: %w", err)
+	}
+
+	return writePNG(GetDefaultImageForCategory(category.ID), img)
+}
+
+// writePNG encodes an image into the images directory
+func writePNG(filename string, img image.Image) error {
+	imagesDir, err := GetImagesDir()
+	if err != nil {
+		return err
+	}
+
+	file, err := os.Create(filepath.Join(imagesDir, filename))
+	if err != nil {
+		return err
+	}
+
+	err = png.Encode(file, img)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	return err
 }
 
 // GetDefaultImageForCategory returns the filename of the default image for a category
@@ -0,0 +1,102 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// IntervalUnit is the calendar unit a billing interval is measured in
+type IntervalUnit string
+
+const (
+	Day   IntervalUnit = "day"
+	Week  IntervalUnit = "week"
+	Month IntervalUnit = "month"
+	Year  IntervalUnit = "year"
+)
+
+// BillingInterval is a billing period expressed as a count of units (e.g. 2 weeks, 3 months)
+type BillingInterval struct {
+	Unit  IntervalUnit `json:"unit"`
+	Count int          `json:"count"`
+}
+
+// Interval returns the billing interval for a preset cycle
+// Custom (and unknown) cycles fall back to one month
+func (c BillingCycle) Interval() BillingInterval {
+	switch c {
+	case Weekly:
+		return BillingInterval{Unit: Week, Count: 1}
+	case Quarterly:
+		return BillingInterval{Unit: Month, Count: 3}
+	case SemiAnnual:
+		return BillingInterval{Unit: Month, Count: 6}
+	case Yearly:
+		return BillingInterval{Unit: Year, Count: 1}
+	default:
+		return BillingInterval{Unit: Month, Count: 1}
+	}
+}
+
+// Interval returns the effective billing interval of the subscription
+func (s Subscription) Interval() BillingInterval {
+	if s.BillingCycle == Custom && s.CustomInterval != nil && s.CustomInterval.Count > 0 {
+		return *s.CustomInterval
+	}
+	return s.BillingCycle.Interval()
+}
+
+// CycleLabel returns a human-readable billing cycle (e.g. "monthly", "every 2 weeks")
+func (s Subscription) CycleLabel() string {
+	if s.BillingCycle == Custom {
+		return "every " + s.Interval().String()
+	}
+	return string(s.BillingCycle)
+}
+
+// Next returns the date one interval after t
+func (i BillingInterval) Next(t time.Time) time.Time {
+	count := i.Count
+	if count < 1 {
+		count = 1
+	}
+
+	switch i.Unit {
+	case Day:
+		return t.AddDate(0, 0, count)
+	case Week:
+		return t.AddDate(0, 0, 7*count)
+	case Year:
+		return t.AddDate(count, 0, 0)
+	default:
+		return t.AddDate(0, count, 0)
+	}
+}
+
+// PerYear returns how many times the interval occurs in a year as the exact
+// fraction num/den (e.g. 52/2 for every 2 weeks)
+func (i BillingInterval) PerYear() (num, den int64) {
+	count := int64(i.Count)
+	if count < 1 {
+		count = 1
+	}
+
+	switch i.Unit {
+	case Day:
+		return 365, count
+	case Week:
+		return 52, count
+	case Year:
+		return 1, count
+	default:
+		return 12, count
+	}
+}
+
+// String formats the interval as "month", "2 weeks", etc.
+func (i BillingInterval) String() string {
+	if i.Count <= 1 {
+		return string(i.Unit)
+	}
+	return fmt.Sprintf("%d %ss", i.Count, i.Unit)
+}
@@ -0,0 +1,58 @@
+package models
+
+import (
+	"time"
+)
+
+// BudgetScope is what a budget limits: all subscriptions, one category or one tag
+type BudgetScope string
+
+const (
+	BudgetOverall  BudgetScope = "overall"
+	BudgetCategory BudgetScope = "category"
+	BudgetTag      BudgetScope = "tag"
+)
+
+// Budget is a monthly spending limit for subscriptions
+type Budget struct {
+	ID        string      `json:"id"`
+	Scope     BudgetScope `json:"scope"`
+	Target    string      `json:"target,omitempty"` // Category ID or tag; empty for the overall budget
+	Limit     Money       `json:"limit"`            // Per month
+	CreatedAt time.Time   `json:"created_at"`
+	UpdatedAt time.Time   `json:"updated_at"`
+}
+
+// Applies reports whether the subscription counts towards the budget
+func (b Budget) Applies(sub Subscription) bool {
+	switch b.Scope {
+	case BudgetCategory:
+		return sub.Category == Category(b.Target)
+	case BudgetTag:
+		return sub.HasTag(b.Target)
+	default:
+		return true
+	}
+}
+
+// String describes the budget's scope, e.g. "Overall" or "Tag: work"
+func (b Budget) String() string {
+	switch b.Scope {
+	case BudgetCategory:
+		return "Category: " + b.Target
+	case BudgetTag:
+		return "Tag: " + b.Target
+	default:
+		return "Overall"
+	}
+}
+
+// FindBudget returns the budget for a scope and target, if one is set
+func (l *SubscriptionList) FindBudget(scope BudgetScope, target string) (*Budget, bool) {
+	for i := range l.Budgets {
+		if l.Budgets[i].Scope == scope && l.Budgets[i].Target == target {
+			return &l.Budgets[i], true
+		}
+	}
+	return nil, false
+}
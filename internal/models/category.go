@@ -0,0 +1,100 @@
+package models
+
+import (
+	"strings"
+	"unicode"
+)
+
+// CategoryDef is an entry in the user-maintained category registry
+// Subscriptions refer to categories by ID, so renaming a category keeps its subscriptions
+type CategoryDef struct {
+	ID         Category `json:"id"`
+	Name       string   `json:"name"`
+	Color      string   `json:"color"`                 // Hex colour such as "#e53935"
+	CustomIcon bool     `json:"custom_icon,omitempty"` // Icon supplied by the user rather than generated from Color
+}
+
+// DefaultCategoryColor is used for categories created without a colour
+const DefaultCategoryColor = "#95a5a6"
+
+// DefaultCategories returns the categories every new data file starts with
+func DefaultCategories() []CategoryDef {
+	return []CategoryDef{
+		{ID: Streaming, Name: "Streaming", Color: "#e53935"},
+		{ID: Software, Name: "Software", Color: "#3498db"},
+		{ID: Utilities, Name: "Utilities", Color: "#2ecc71"},
+		{ID: Gaming, Name: "Gaming", Color: "#9b59b6"},
+		{ID: News, Name: "News", Color: "#f1c40f"},
+		{ID: Education, Name: "Education", Color: "#1abc9c"},
+		{ID: Creator, Name: "Creator", Color: "#e67e22"},
+		{ID: Other, Name: "Other", Color: DefaultCategoryColor},
+	}
+}
+
+// CategoryID derives a stable category ID from a display name (e.g. "AI tools" -> "ai-tools")
+func CategoryID(name string) Category {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(strings.TrimSpace(name)) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if dash && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			b.WriteRune(r)
+			dash = false
+		} else {
+			dash = true
+		}
+	}
+	return Category(b.String())
+}
+
+// FindCategory returns the registry entry for a category ID
+func (l *SubscriptionList) FindCategory(id Category) (CategoryDef, bool) {
+	for _, def := range l.Categories {
+		if def.ID == id {
+			return def, true
+		}
+	}
+	return CategoryDef{}, false
+}
+
+// EnsureCategories seeds the registry for data saved before categories were
+// user-defined and registers any category a subscription uses but the registry
+// lacks. Reports whether the list changed.
+func (l *SubscriptionList) EnsureCategories() bool {
+	changed := false
+	if len(l.Categories) == 0 {
+		l.Categories = DefaultCategories()
+		changed = true
+	}
+
+	for i, sub := range l.Subscriptions {
+		if sub.Category == "" {
+			l.Subscriptions[i].Category = Other
+			sub.Category = Other
+			changed = true
+		}
+		if _, ok := l.FindCategory(sub.Category); !ok {
+			l.Categories = append(l.Categories, CategoryDef{
+				ID:    sub.Category,
+				Name:  categoryName(sub.Category),
+				Color: DefaultCategoryColor,
+			})
+			changed = true
+		}
+	}
+
+	return changed
+}
+
+// categoryName turns an ID such as "cloud-infra" into "Cloud infra"
+func categoryName(id Category) string {
+	name := strings.ReplaceAll(string(id), "-", " ")
+	if name == "" {
+		return name
+	}
+	runes := []rune(name)
+	runes[0] = unicode.ToUpper(runes[0])
+	return string(runes)
+}
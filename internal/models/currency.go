@@ -0,0 +1,94 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// DefaultCurrency is assumed for records saved before currencies were tracked
+const DefaultCurrency = "USD"
+
+// Currencies lists the ISO 4217 codes offered in the UI
+var Currencies = []string{"USD", "EUR", "GBP", "CAD", "AUD", "NZD", "CHF", "JPY", "SEK", "NOK", "DKK", "PLN", "INR", "BRL", "MXN"}
+
+var currencySymbols = map[string]string{
+	"USD": "$",
+	"EUR": "€",
+	"GBP": "£",
+	"JPY": "¥",
+	"INR": "₹",
+}
+
+// ExchangeRates is the user-maintained (offline) exchange-rate table
+// Rates are expressed as units of Base per one unit of the keyed currency
+type ExchangeRates struct {
+	Base      string             `json:"base"`
+	Rates     map[string]float64 `json:"rates"`
+	UpdatedAt time.Time          `json:"updated_at"`
+}
+
+func currencyOrDefault(code string) string {
+	if code == "" {
+		return DefaultCurrency
+	}
+	return code
+}
+
+// BaseCurrency returns the base/display currency of the table
+func (r *ExchangeRates) BaseCurrency() string {
+	if r == nil {
+		return DefaultCurrency
+	}
+	return currencyOrDefault(r.Base)
+}
+
+// Rate returns how many units of the base currency one unit of code is worth
+func (r *ExchangeRates) Rate(code string) (float64, bool) {
+	code = currencyOrDefault(code)
+	if code == r.BaseCurrency() {
+		return 1, true
+	}
+	if r == nil {
+		return 0, false
+	}
+	rate, ok := r.Rates[code]
+	return rate, ok && rate > 0
+}
+
+// ToBase converts an amount to the base currency, rounding to the nearest minor unit
+// Returns false (and the amount counted 1:1 in the base currency) when no rate is known
+func (r *ExchangeRates) ToBase(m Money) (Money, bool) {
+	base := r.BaseCurrency()
+	rate, ok := r.Rate(m.CurrencyCode())
+	if !ok {
+		rate = 1
+	}
+	if m.CurrencyCode() == base {
+		return NewMoney(m.Amount, base), true
+	}
+	return MoneyFromFloat(m.Float()*rate, base), ok
+}
+
+// Rebase re-expresses every rate relative to a new base currency
+func (r *ExchangeRates) Rebase(base string) error {
+	if base == r.BaseCurrency() {
+		return nil
+	}
+
+	newBaseRate, ok := r.Rate(base)
+	if !ok {
+		return fmt.Errorf("no exchange rate for %s; add one before making it the base currency", base)
+	}
+
+	rates := map[string]float64{r.BaseCurrency(): 1 / newBaseRate}
+	for code, rate := range r.Rates {
+		if code != base && rate > 0 {
+			rates[code] = rate / newBaseRate
+		}
+	}
+
+	r.Base = base
+	r.Rates = rates
+	r.UpdatedAt = time.Now()
+	return nil
+}
@@ -0,0 +1,84 @@
+package models
+
+import (
+	"bytes"
+	"encoding/json"
+)
+
+// UnmarshalJSON reads both the current format and older files where costs were
+// bare floats with an optional sibling "currency" field
+func (s *Subscription) UnmarshalJSON(data []byte) error {
+	type plain Subscription
+	aux := struct {
+		*plain
+		Cost          json.RawMessage `json:"cost"`
+		PostTrialCost json.RawMessage `json:"post_trial_cost"`
+		Currency      string          `json:"currency"` // Legacy sibling of a float cost
+	}{plain: (*plain)(s)}
+
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+
+	var err error
+	if s.Cost, err = decodeMoney(aux.Cost, aux.Currency); err != nil {
+		return err
+	}
+	if s.PostTrialCost, err = decodeMoney(aux.PostTrialCost, s.Cost.Currency); err != nil {
+		return err
+	}
+	return nil
+}
+
+// UnmarshalJSON reads both the current format and older float amounts
+// Records saved before payments had a source are classified by their note, and
+// records saved before payments had a type are charges
+func (p *Payment) UnmarshalJSON(data []byte) error {
+	type plain Payment
+	aux := struct {
+		*plain
+		Amount   json.RawMessage `json:"amount"`
+		Currency string          `json:"currency"` // Legacy sibling of a float amount
+	}{plain: (*plain)(p)}
+
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+
+	var err error
+	if p.Amount, err = decodeMoney(aux.Amount, aux.Currency); err != nil {
+		return err
+	}
+
+	if p.Type == "" {
+		p.Type = PaymentCharged
+	}
+
+	if p.Source == "" {
+		p.Source = SourceManual
+		if p.Notes == AutoGeneratedNote {
+			p.Source = SourceGenerated
+		}
+	}
+	return nil
+}
+
+// decodeMoney accepts a Money object or a legacy decimal number
+func decodeMoney(raw json.RawMessage, legacyCurrency string) (Money, error) {
+	raw = bytes.TrimSpace(raw)
+	if len(raw) == 0 || bytes.Equal(raw, []byte("null")) {
+		return Zero(legacyCurrency), nil
+	}
+
+	if raw[0] == '{' {
+		var m Money
+		if err := json.Unmarshal(raw, &m); err != nil {
+			return Money{}, err
+		}
+		m.Currency = currencyOrDefault(m.Currency)
+		return m, nil
+	}
+
+	// Legacy float - parse the literal exactly rather than via float64
+	return ParseMoney(string(raw), legacyCurrency)
+}
@@ -0,0 +1,139 @@
+package models
+
+import (
+	"math"
+	"time"
+)
+
+// HasTrial reports whether the subscription was set up with a free trial
+func (s Subscription) HasTrial() bool {
+	return !s.TrialEndDate.IsZero()
+}
+
+// InTrial reports whether the subscription is still in its free trial at t
+func (s Subscription) InTrial(t time.Time) bool {
+	return s.HasTrial() && t.Before(s.TrialEndDate)
+}
+
+// TrialDaysLeft returns the number of whole days until the trial converts to paid
+func (s Subscription) TrialDaysLeft(t time.Time) int {
+	if !s.InTrial(t) {
+		return 0
+	}
+	return int(math.Ceil(s.TrialEndDate.Sub(t).Hours() / 24))
+}
+
+// BilledCost returns the price charged per billing period once any trial has converted
+func (s Subscription) BilledCost() Money {
+	if s.HasTrial() && !s.PostTrialCost.IsZero() {
+		return s.PostTrialCost
+	}
+	return s.Cost
+}
+
+// PausePeriod is a span during which a subscription is not billed
+type PausePeriod struct {
+	Start  time.Time `json:"start"`
+	Resume time.Time `json:"resume"` // Resume (or auto-resume) date; zero while paused indefinitely
+}
+
+// Contains reports whether t falls inside the pause
+func (p PausePeriod) Contains(t time.Time) bool {
+	return !t.Before(p.Start) && (p.Resume.IsZero() || t.Before(p.Resume))
+}
+
+// PausedAt reports whether billing was paused on date t
+func (s Subscription) PausedAt(t time.Time) bool {
+	for _, pause := range s.Pauses {
+		if pause.Contains(t) {
+			return true
+		}
+	}
+	return false
+}
+
+// CurrentPause returns the pause the subscription is in, if any
+func (s Subscription) CurrentPause() (*PausePeriod, bool) {
+	if !s.Paused || len(s.Pauses) == 0 {
+		return nil, false
+	}
+	return &s.Pauses[len(s.Pauses)-1], true
+}
+
+// PausedUntil returns the auto-resume date of the current pause (zero if none)
+func (s Subscription) PausedUntil() time.Time {
+	if pause, ok := s.CurrentPause(); ok {
+		return pause.Resume
+	}
+	return time.Time{}
+}
+
+// SetPaused pauses or resumes the subscription at now, recording pause history
+// resume is the optional auto-resume date for a pause
+func (s *Subscription) SetPaused(paused bool, resume time.Time, now time.Time) {
+	// Paused before pause history was kept: the pause began no later than the last edit
+	if s.Paused && len(s.Pauses) == 0 {
+		start := s.UpdatedAt
+		if start.IsZero() || start.After(now) {
+			start = now
+		}
+		s.Pauses = append(s.Pauses, PausePeriod{Start: start})
+	}
+
+	pause, inPause := s.CurrentPause()
+
+	switch {
+	case paused && !inPause:
+		s.Pauses = append(s.Pauses, PausePeriod{Start: now, Resume: resume})
+	case paused && inPause:
+		pause.Resume = resume
+	case !paused && inPause && (pause.Resume.IsZero() || pause.Resume.After(now)):
+		// Resumed early or by hand - close the pause today
+		pause.Resume = now
+	}
+
+	s.Paused = paused
+}
+
+// ResumeDue reports whether a paused subscription has reached its auto-resume date
+func (s Subscription) ResumeDue(now time.Time) bool {
+	resume := s.PausedUntil()
+	return s.Paused && !resume.IsZero() && !now.Before(resume)
+}
+
+// SubscriptionStatus is the lifecycle state of a subscription
+type SubscriptionStatus string
+
+const (
+	StatusActive    SubscriptionStatus = "active"
+	StatusTrial     SubscriptionStatus = "trial"
+	StatusPaused    SubscriptionStatus = "paused"
+	StatusCancelled SubscriptionStatus = "cancelled"
+)
+
+// Status returns the lifecycle state at t; cancellation takes precedence
+func (s Subscription) Status(t time.Time) SubscriptionStatus {
+	switch {
+	case s.IsCancelled():
+		return StatusCancelled
+	case s.Paused:
+		return StatusPaused
+	case s.InTrial(t):
+		return StatusTrial
+	default:
+		return StatusActive
+	}
+}
+
+// IsCancelled reports whether the subscription has been cancelled
+func (s Subscription) IsCancelled() bool {
+	return !s.CancelledAt.IsZero()
+}
+
+// HasAccess reports whether a cancelled subscription can still be used at t
+func (s Subscription) HasAccess(t time.Time) bool {
+	if !s.IsCancelled() {
+		return true
+	}
+	return t.Before(s.AccessEndsAt)
+}
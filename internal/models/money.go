@@ -0,0 +1,220 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Money is an exact amount stored in minor units (e.g. cents) of a currency
+//
+// Rounding rules: whenever an amount has to be divided (monthly equivalents,
+// exchange-rate conversion, parsing input with too many decimals) the result is
+// rounded to the nearest minor unit, with ties going to the even unit (banker's
+// rounding). Totals are sums of already-rounded amounts.
+type Money struct {
+	Amount   int64  `json:"amount"`   // Minor units, e.g. 1599 for $15.99
+	Currency string `json:"currency"` // ISO 4217 code
+}
+
+// zeroDecimalCurrencies have no minor unit
+var zeroDecimalCurrencies = map[string]bool{
+	"JPY": true,
+	"KRW": true,
+}
+
+var ErrInvalidAmount = errors.New("invalid amount")
+
+// CurrencyDecimals returns the number of minor-unit digits for a currency
+func CurrencyDecimals(code string) int {
+	if zeroDecimalCurrencies[currencyOrDefault(code)] {
+		return 0
+	}
+	return 2
+}
+
+// NewMoney creates an amount from minor units
+func NewMoney(minor int64, currency string) Money {
+	return Money{Amount: minor, Currency: currencyOrDefault(currency)}
+}
+
+// Zero returns a zero amount in the given currency
+func Zero(currency string) Money {
+	return NewMoney(0, currency)
+}
+
+// MoneyFromFloat converts a floating point major-unit amount, rounding to the nearest minor unit
+func MoneyFromFloat(amount float64, currency string) Money {
+	scale := math.Pow10(CurrencyDecimals(currency))
+	return NewMoney(int64(math.RoundToEven(amount*scale)), currency)
+}
+
+// ParseMoney parses a decimal string such as "15.99" or "1,299.5" exactly,
+// without going through float64
+func ParseMoney(text string, currency string) (Money, error) {
+	text = strings.ReplaceAll(strings.TrimSpace(text), ",", "")
+	if text == "" {
+		return Money{}, ErrInvalidAmount
+	}
+
+	negative := false
+	if text[0] == '-' || text[0] == '+' {
+		negative = text[0] == '-'
+		text = text[1:]
+	}
+
+	whole, frac, _ := strings.Cut(text, ".")
+	if whole == "" && frac == "" {
+		return Money{}, ErrInvalidAmount
+	}
+	for _, part := range []string{whole, frac} {
+		for _, r := range part {
+			if r < '0' || r > '9' {
+				return Money{}, fmt.Errorf("%w: %q", ErrInvalidAmount, text)
+			}
+		}
+	}
+
+	decimals := CurrencyDecimals(currency)
+	scale := int64(math.Pow10(decimals))
+
+	// Keep the digits that fit in minor units, round on the rest
+	kept := frac
+	rest := ""
+	if len(frac) > decimals {
+		kept, rest = frac[:decimals], frac[decimals:]
+	}
+	kept += strings.Repeat("0", decimals-len(kept))
+	var f int64
+	if kept != "" {
+		var err error
+		if f, err = strconv.ParseInt(kept, 10, 64); err != nil {
+			return Money{}, fmt.Errorf("%w: %q", ErrInvalidAmount, text)
+		}
+	}
+
+	var w int64
+	if whole != "" {
+		var err error
+		if w, err = strconv.ParseInt(whole, 10, 64); err != nil {
+			return Money{}, fmt.Errorf("%w: %q", ErrInvalidAmount, text)
+		}
+	}
+	// The amount in minor units must fit in an int64
+	if w > (math.MaxInt64-f)/scale {
+		return Money{}, fmt.Errorf("%w: %q is too large", ErrInvalidAmount, text)
+	}
+	minor := w*scale + f
+	if rest != "" && roundUpRemainder(rest, minor) {
+		if minor == math.MaxInt64 {
+			return Money{}, fmt.Errorf("%w: %q is too large", ErrInvalidAmount, text)
+		}
+		minor++
+	}
+
+	if negative {
+		minor = -minor
+	}
+	return NewMoney(minor, currency), nil
+}
+
+// roundUpRemainder decides half-to-even rounding for the dropped digits
+func roundUpRemainder(rest string, kept int64) bool {
+	switch {
+	case rest[0] > '5':
+		return true
+	case rest[0] < '5':
+		return false
+	case strings.Trim(rest[1:], "0") != "":
+		return true
+	default:
+		return kept%2 != 0
+	}
+}
+
+// MulDiv returns m * num / den rounded half-to-even to the nearest minor unit
+func (m Money) MulDiv(num, den int64) Money {
+	if den == 0 {
+		return m
+	}
+	return Money{Amount: roundDiv(m.Amount*num, den), Currency: m.Currency}
+}
+
+// roundDiv divides with half-to-even rounding
+func roundDiv(num, den int64) int64 {
+	if den < 0 {
+		num, den = -num, -den
+	}
+	q, r := num/den, num%den
+	if r < 0 {
+		r = -r
+	}
+	switch {
+	case 2*r > den, 2*r == den && q%2 != 0:
+		if num < 0 {
+			q--
+		} else {
+			q++
+		}
+	}
+	return q
+}
+
+// Add returns the sum of two amounts in the same currency
+func (m Money) Add(o Money) Money {
+	if m.Currency == "" {
+		m.Currency = o.Currency
+	}
+	return Money{Amount: m.Amount + o.Amount, Currency: m.Currency}
+}
+
+// Neg returns the amount with its sign flipped
+func (m Money) Neg() Money {
+	return Money{Amount: -m.Amount, Currency: m.Currency}
+}
+
+// IsZero reports whether the amount is zero
+func (m Money) IsZero() bool {
+	return m.Amount == 0
+}
+
+// CurrencyCode returns the currency, defaulting legacy values to USD
+func (m Money) CurrencyCode() string {
+	return currencyOrDefault(m.Currency)
+}
+
+// Float returns the amount in major units; use only for display and ratios
+func (m Money) Float() float64 {
+	return float64(m.Amount) / math.Pow10(CurrencyDecimals(m.Currency))
+}
+
+// Decimal formats the amount without a currency symbol (e.g. "15.99")
+func (m Money) Decimal() string {
+	decimals := CurrencyDecimals(m.Currency)
+	amount := m.Amount
+	sign := ""
+	if amount < 0 {
+		sign = "-"
+		amount = -amount
+	}
+	if decimals == 0 {
+		return fmt.Sprintf("%s%d", sign, amount)
+	}
+	scale := int64(math.Pow10(decimals))
+	return fmt.Sprintf("%s%d.%0*d", sign, amount/scale, decimals, amount%scale)
+}
+
+// String formats the amount with its currency symbol (e.g. "$12.00", "12.00 CHF")
+func (m Money) String() string {
+	code := m.CurrencyCode()
+	decimal := m.Decimal()
+	if symbol, ok := currencySymbols[code]; ok {
+		if strings.HasPrefix(decimal, "-") {
+			return "-" + symbol + decimal[1:]
+		}
+		return symbol + decimal
+	}
+	return decimal + " " + code
+}
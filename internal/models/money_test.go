@@ -0,0 +1,57 @@
+package models
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseMoney(t *testing.T) {
+	tests := []struct {
+		text     string
+		currency string
+		want     int64
+	}{
+		{"15.99", "USD", 1599},
+		{"1,299.5", "USD", 129950},
+		{"-3", "USD", -300},
+		{".5", "USD", 50},
+		{"0.125", "USD", 12}, // Ties go to the even unit
+		{"0.135", "USD", 14},
+		{"1500", "JPY", 1500},
+		{"92233720368547758.07", "USD", 9223372036854775807}, // Largest amount that fits
+		{"9223372036854775807", "JPY", 9223372036854775807},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseMoney(tt.text, tt.currency)
+		if err != nil {
+			t.Errorf("ParseMoney(%q, %s): %v", tt.text, tt.currency, err)
+			continue
+		}
+		if got != NewMoney(tt.want, tt.currency) {
+			t.Errorf("ParseMoney(%q, %s) = %v, want %d", tt.text, tt.currency, got, tt.want)
+		}
+	}
+}
+
+func TestParseMoneyInvalid(t *testing.T) {
+	tests := []struct {
+		text     string
+		currency string
+	}{
+		{"", "USD"},
+		{"-", "USD"},
+		{"12a", "USD"},
+		{"1.2.3", "USD"},
+		{"92233720368547758.08", "USD"},  // One minor unit too many
+		{"92233720368547759", "USD"},     // Whole part overflows once scaled
+		{"92233720368547758.075", "USD"}, // Rounds up past the largest amount
+		{"9223372036854775808", "JPY"},
+	}
+
+	for _, tt := range tests {
+		if got, err := ParseMoney(tt.text, tt.currency); !errors.Is(err, ErrInvalidAmount) {
+			t.Errorf("ParseMoney(%q, %s) = %v, %v, want ErrInvalidAmount", tt.text, tt.currency, got, err)
+		}
+	}
+}
@@ -0,0 +1,92 @@
+package models
+
+import "time"
+
+// PaymentSource records where a payment record came from
+type PaymentSource string
+
+const (
+	SourceGenerated PaymentSource = "generated" // Created from the billing schedule; may be re-priced or regenerated
+	SourceManual    PaymentSource = "manual"    // Entered, edited or confirmed by the user; never touched by regeneration
+)
+
+// AutoGeneratedNote is the note put on payments created from the billing schedule
+const AutoGeneratedNote = "Auto-generated"
+
+// IsGenerated reports whether the payment still comes straight from the billing schedule
+func (p Payment) IsGenerated() bool {
+	return p.Source == SourceGenerated
+}
+
+// SameDay reports whether two payment dates fall on the same calendar day
+// Each date is read in its own location, so a date entered at local midnight
+// matches the UTC billing date of the same day.
+func SameDay(a, b time.Time) bool {
+	return CalendarDay(a).Equal(CalendarDay(b))
+}
+
+// CalendarDay returns midnight UTC of the calendar day t falls on in its own location
+// Billing dates are stored this way, so days compare alike in every time zone.
+func CalendarDay(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+// IsSkippedPayment reports whether the user removed the scheduled payment on date
+func (s Subscription) IsSkippedPayment(date time.Time) bool {
+	for _, skipped := range s.SkippedPayments {
+		if SameDay(skipped, date) {
+			return true
+		}
+	}
+	return false
+}
+
+// SkipPayment records that the scheduled payment on date should not be generated again
+func (s *Subscription) SkipPayment(date time.Time) {
+	if !s.IsSkippedPayment(date) {
+		s.SkippedPayments = append(s.SkippedPayments, date)
+	}
+}
+
+// PaymentType is what happened to a payment
+type PaymentType string
+
+const (
+	PaymentCharged  PaymentType = "charged"  // Money taken; positive amount
+	PaymentRefunded PaymentType = "refunded" // Money given back; negative amount
+	PaymentCredit   PaymentType = "credit"   // Prorated or goodwill credit; negative amount
+	PaymentFailed   PaymentType = "failed"   // Declined charge; not counted as spent
+	PaymentPending  PaymentType = "pending"  // Not settled yet; not counted as spent
+)
+
+// PaymentTypes lists the payment types in display order
+var PaymentTypes = []PaymentType{PaymentCharged, PaymentRefunded, PaymentCredit, PaymentFailed, PaymentPending}
+
+// IsSettled reports whether the payment moved money and counts towards spending
+func (p Payment) IsSettled() bool {
+	switch p.Type {
+	case PaymentFailed, PaymentPending:
+		return false
+	default:
+		return true
+	}
+}
+
+// Spent returns the signed amount the payment adds to spending: negative for
+// refunds and credits, zero for failed and pending payments
+func (p Payment) Spent() Money {
+	if !p.IsSettled() {
+		return Zero(p.Amount.Currency)
+	}
+	return p.Amount
+}
+
+// NormalizeSign stores refunds and credits as negative amounts and everything
+// else as positive, whichever sign was entered
+func (p *Payment) NormalizeSign() {
+	negative := p.Type == PaymentRefunded || p.Type == PaymentCredit
+	if (p.Amount.Amount < 0) != negative && p.Amount.Amount != 0 {
+		p.Amount = p.Amount.Neg()
+	}
+}
@@ -0,0 +1,64 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// PaymentMethodType is the kind of account a subscription bills to
+type PaymentMethodType string
+
+const (
+	CardMethod   PaymentMethodType = "card"
+	BankMethod   PaymentMethodType = "bank"
+	PayPalMethod PaymentMethodType = "paypal"
+	OtherMethod  PaymentMethodType = "other"
+)
+
+// PaymentMethod is a card or account subscriptions are billed to
+type PaymentMethod struct {
+	ID          string            `json:"id"`
+	Label       string            `json:"label"` // e.g. "Work Visa"
+	Type        PaymentMethodType `json:"type"`
+	LastFour    string            `json:"last_four,omitempty"`
+	ExpiryMonth time.Time         `json:"expiry_month"` // First day of the expiry month; zero if it doesn't expire
+	CreatedAt   time.Time         `json:"created_at"`
+	UpdatedAt   time.Time         `json:"updated_at"`
+}
+
+// Expires returns the moment the method stops working: the end of its expiry month
+func (m PaymentMethod) Expires() time.Time {
+	if m.ExpiryMonth.IsZero() {
+		return time.Time{}
+	}
+	start := time.Date(m.ExpiryMonth.Year(), m.ExpiryMonth.Month(), 1, 0, 0, 0, 0, m.ExpiryMonth.Location())
+	return start.AddDate(0, 1, 0)
+}
+
+// ExpiresWithin reports whether the method expires (or has expired) before now plus days
+func (m PaymentMethod) ExpiresWithin(now time.Time, days int) bool {
+	expires := m.Expires()
+	return !expires.IsZero() && expires.Before(now.AddDate(0, 0, days))
+}
+
+// String describes the method, e.g. "Work Visa ••1234 (exp 03/2027)"
+func (m PaymentMethod) String() string {
+	text := m.Label
+	if m.LastFour != "" {
+		text += " ••" + m.LastFour
+	}
+	if !m.ExpiryMonth.IsZero() {
+		text += fmt.Sprintf(" (exp %s)", m.ExpiryMonth.Format("01/2006"))
+	}
+	return text
+}
+
+// FindPaymentMethod returns the payment method with the given ID
+func (l *SubscriptionList) FindPaymentMethod(id string) (PaymentMethod, bool) {
+	for _, method := range l.PaymentMethods {
+		if method.ID == id {
+			return method, true
+		}
+	}
+	return PaymentMethod{}, false
+}
@@ -0,0 +1,127 @@
+package models
+
+import (
+	"sort"
+	"time"
+)
+
+// PriceChange records the price in force from a given date
+type PriceChange struct {
+	EffectiveFrom time.Time `json:"effective_from"`
+	Cost          Money     `json:"cost"`
+}
+
+// PriceAt returns the price that was in force on the calendar day of t
+// Without a recorded history the current billed cost applies to every date
+func (s Subscription) PriceAt(t time.Time) Money {
+	if len(s.PriceHistory) == 0 {
+		return s.BilledCost()
+	}
+
+	day := CalendarDay(t)
+	price := s.PriceHistory[0].Cost
+	for _, change := range s.PriceHistory {
+		if CalendarDay(change.EffectiveFrom).After(day) {
+			break
+		}
+		price = change.Cost
+	}
+	return price
+}
+
+// OriginalPrice returns the price the subscription started at
+func (s Subscription) OriginalPrice() Money {
+	if len(s.PriceHistory) == 0 {
+		return s.BilledCost()
+	}
+	return s.PriceHistory[0].Cost
+}
+
+// PriceChangePercent returns how much the price at t differs from the original
+// price, in percent; ok is false when there is nothing comparable
+func (s Subscription) PriceChangePercent(t time.Time) (percent float64, ok bool) {
+	original := s.OriginalPrice()
+	current := s.PriceAt(t)
+	if original.Amount == 0 || original.CurrencyCode() != current.CurrencyCode() || original.Amount == current.Amount {
+		return 0, false
+	}
+	return float64(current.Amount-original.Amount) / float64(original.Amount) * 100, true
+}
+
+// RecordPriceChange adds a dated price to the history, seeding it with the
+// original price on first use so every date has a known price
+// The change takes effect from the start of effective's calendar day.
+func (s *Subscription) RecordPriceChange(previous Money, cost Money, effective time.Time) {
+	if len(s.PriceHistory) == 0 {
+		s.PriceHistory = append(s.PriceHistory, PriceChange{EffectiveFrom: s.BillingStart(), Cost: previous})
+	}
+
+	// Replace an entry for the same day rather than stacking duplicates
+	day := CalendarDay(effective)
+	history := s.PriceHistory[:0]
+	for _, change := range s.PriceHistory {
+		if !SameDay(change.EffectiveFrom, day) {
+			history = append(history, change)
+		}
+	}
+	history = append(history, PriceChange{EffectiveFrom: day, Cost: cost})
+	sort.SliceStable(history, func(i, j int) bool {
+		return history[i].EffectiveFrom.Before(history[j].EffectiveFrom)
+	})
+	s.PriceHistory = history
+}
+
+// BillingStart returns the date billing begins: the trial end if there was one, else the start date
+func (s Subscription) BillingStart() time.Time {
+	if s.HasTrial() && s.TrialEndDate.After(s.StartDate) {
+		return s.TrialEndDate
+	}
+	return s.StartDate
+}
+
+// NextPriceChange returns the first scheduled price change after t's calendar day
+func (s Subscription) NextPriceChange(t time.Time) (PriceChange, bool) {
+	day := CalendarDay(t)
+	for _, change := range s.PriceHistory {
+		if CalendarDay(change.EffectiveFrom).After(day) {
+			return change, true
+		}
+	}
+	return PriceChange{}, false
+}
+
+// ApplyPriceSchedule sets Cost (or PostTrialCost during a trial) to the price
+// in force at t and reports whether anything changed
+func (s *Subscription) ApplyPriceSchedule(t time.Time) bool {
+	if len(s.PriceHistory) == 0 {
+		return false
+	}
+
+	if s.InTrial(t) {
+		price := s.PriceAt(s.TrialEndDate)
+		changed := price != s.PostTrialCost
+		s.PostTrialCost = price
+		return changed
+	}
+
+	price := s.PriceAt(t)
+	changed := price != s.Cost
+	s.Cost = price
+	return changed
+}
+
+// SetIntroPrice schedules a promotional price for the first periods billing
+// periods, followed by the regular price
+func (s *Subscription) SetIntroPrice(intro Money, periods int, regular Money) {
+	start := s.BillingStart()
+	regularFrom := start
+	interval := s.Interval()
+	for i := 0; i < periods; i++ {
+		regularFrom = interval.Next(regularFrom)
+	}
+
+	s.PriceHistory = []PriceChange{
+		{EffectiveFrom: start, Cost: intro},
+		{EffectiveFrom: regularFrom, Cost: regular},
+	}
+}
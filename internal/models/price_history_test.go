@@ -0,0 +1,44 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRecordPriceChangeByCalendarDay(t *testing.T) {
+	newYork := time.FixedZone("EDT", -4*60*60)
+	sub := Subscription{
+		Cost:      NewMoney(1000, "USD"),
+		StartDate: time.Date(2026, 1, 17, 0, 0, 0, 0, time.UTC),
+	}
+
+	// Evening in New York, already the next day in UTC
+	changed := time.Date(2026, 10, 17, 21, 0, 0, 0, newYork)
+	sub.RecordPriceChange(sub.Cost, NewMoney(1200, "USD"), changed)
+
+	// Changed again later the same day: the second price replaces the first
+	sub.RecordPriceChange(NewMoney(1200, "USD"), NewMoney(1300, "USD"), changed.Add(2*time.Hour))
+	if len(sub.PriceHistory) != 2 {
+		t.Fatalf("got %d price history entries, want the original and one change", len(sub.PriceHistory))
+	}
+
+	tests := []struct {
+		date time.Time
+		want int64
+	}{
+		{time.Date(2026, 9, 17, 0, 0, 0, 0, time.UTC), 1000},
+		{time.Date(2026, 10, 16, 0, 0, 0, 0, time.UTC), 1000},
+		{time.Date(2026, 10, 17, 0, 0, 0, 0, time.UTC), 1300}, // Billed the day of the change
+		{changed, 1300},
+		{time.Date(2026, 10, 17, 8, 0, 0, 0, newYork), 1300}, // Earlier the same local day
+	}
+	for _, tt := range tests {
+		if got := sub.PriceAt(tt.date); got.Amount != tt.want {
+			t.Errorf("PriceAt(%v) = %v, want %d", tt.date, got, tt.want)
+		}
+	}
+
+	if _, ok := sub.NextPriceChange(changed); ok {
+		t.Error("a change made today is reported as upcoming")
+	}
+}
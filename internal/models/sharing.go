@@ -0,0 +1,138 @@
+package models
+
+import (
+	"errors"
+	"math"
+)
+
+// Me is the participant name used for the owner of the data file
+const Me = "Me"
+
+// ErrSharesExceedCost is returned when fixed shares add up to more than the subscription costs
+var ErrSharesExceedCost = errors.New("fixed shares add up to more than the subscription costs")
+
+// SplitType decides how a shared subscription's cost is divided
+type SplitType string
+
+const (
+	SplitEqual   SplitType = "equal"   // Everyone, including me, pays the same
+	SplitPercent SplitType = "percent" // Each participant pays a percentage; I pay the rest
+	SplitFixed   SplitType = "fixed"   // Each participant pays a fixed amount per charge; I pay the rest
+)
+
+// Participant is someone other than me who shares a subscription
+type Participant struct {
+	Name    string  `json:"name"`
+	Percent float64 `json:"percent,omitempty"` // Used by SplitPercent
+	Fixed   Money   `json:"fixed"`             // Used by SplitFixed, per charge in the subscription's currency
+}
+
+// Sharing describes how a subscription is split between me and other people
+type Sharing struct {
+	Split        SplitType     `json:"split"`
+	PaidBy       string        `json:"paid_by,omitempty"` // Participant who pays the provider; empty means me
+	Participants []Participant `json:"participants"`
+}
+
+// Share is one person's part of a charge
+type Share struct {
+	Name   string
+	Amount Money
+}
+
+// IsShared reports whether the subscription is split with anyone
+func (s Subscription) IsShared() bool {
+	return s.Sharing != nil && len(s.Sharing.Participants) > 0
+}
+
+// Payer returns who pays the provider for the subscription
+func (s Subscription) Payer() string {
+	if !s.IsShared() || s.Sharing.PaidBy == "" {
+		return Me
+	}
+	return s.Sharing.PaidBy
+}
+
+// Shares splits a charge between me and the participants, me first
+// Any rounding difference or unallocated remainder is mine, so the shares
+// always add up to the charge.
+func (s Subscription) Shares(amount Money) []Share {
+	if !s.IsShared() {
+		return []Share{{Name: Me, Amount: amount}}
+	}
+
+	participants := s.Sharing.Participants
+	shares := make([]Share, 0, len(participants)+1)
+	shares = append(shares, Share{Name: Me})
+
+	mine := amount
+	switch s.Sharing.Split {
+	case SplitPercent:
+		for _, p := range participants {
+			basisPoints := int64(math.Round(p.Percent * 100))
+			part := amount.MulDiv(basisPoints, 10000)
+			shares = append(shares, Share{Name: p.Name, Amount: part})
+			mine = mine.Add(part.Neg())
+		}
+	case SplitFixed:
+		// A refund or credit is given back in proportion to each fixed share
+		// of the price, never more in total than was refunded
+		basis := s.BilledCost().Amount
+		if fixed := s.Sharing.fixedTotal(); fixed > basis {
+			basis = fixed
+		}
+		for _, p := range participants {
+			part := NewMoney(p.Fixed.Amount, amount.Currency)
+			if amount.Amount < 0 {
+				part = Zero(amount.Currency)
+				if basis > 0 {
+					part = amount.MulDiv(p.Fixed.Amount, basis)
+				}
+				if part.Amount < mine.Amount {
+					part.Amount = mine.Amount
+				}
+			}
+			shares = append(shares, Share{Name: p.Name, Amount: part})
+			mine = mine.Add(part.Neg())
+		}
+	default:
+		// Equal split: everyone gets the rounded-down part, I absorb the remainder
+		part := amount.MulDiv(1, int64(len(participants)+1))
+		if part.Amount*int64(len(participants)+1) > amount.Amount {
+			part.Amount--
+		}
+		for _, p := range participants {
+			shares = append(shares, Share{Name: p.Name, Amount: part})
+			mine = mine.Add(part.Neg())
+		}
+	}
+
+	shares[0].Amount = mine
+	return shares
+}
+
+// MyShare returns my part of a charge for the subscription
+func (s Subscription) MyShare(amount Money) Money {
+	return s.Shares(amount)[0].Amount
+}
+
+// ValidateSharing checks that fixed shares leave me a part of the price that
+// isn't negative
+func (s Subscription) ValidateSharing() error {
+	if !s.IsShared() || s.Sharing.Split != SplitFixed {
+		return nil
+	}
+	if s.Sharing.fixedTotal() > s.BilledCost().Amount {
+		return ErrSharesExceedCost
+	}
+	return nil
+}
+
+// fixedTotal returns the sum of the participants' fixed shares in minor units
+func (s Sharing) fixedTotal() int64 {
+	var total int64
+	for _, p := range s.Participants {
+		total += p.Fixed.Amount
+	}
+	return total
+}
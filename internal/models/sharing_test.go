@@ -0,0 +1,70 @@
+package models
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestSharesFixed(t *testing.T) {
+	sub := Subscription{Cost: NewMoney(2000, "USD"), Sharing: &Sharing{
+		Split: SplitFixed,
+		Participants: []Participant{
+			{Name: "Bob", Fixed: NewMoney(500, "USD")},
+			{Name: "Ann", Fixed: NewMoney(300, "USD")},
+		},
+	}}
+
+	tests := []struct {
+		name   string
+		amount int64
+		want   []int64 // Me, Bob, Ann
+	}{
+		{"charge", 2000, []int64{1200, 500, 300}},
+		{"refund", -2000, []int64{-1200, -500, -300}},
+		{"partial refund", -600, []int64{-360, -150, -90}},
+		{"small refund", -201, []int64{-121, -50, -30}},
+		{"refund larger than the charge", -3000, []int64{-1800, -750, -450}},
+	}
+
+	for _, tt := range tests {
+		shares := sub.Shares(NewMoney(tt.amount, "USD"))
+		if len(shares) != len(tt.want) {
+			t.Fatalf("%s: got %d shares, want %d", tt.name, len(shares), len(tt.want))
+		}
+
+		var total int64
+		for i, share := range shares {
+			if share.Amount.Amount != tt.want[i] {
+				t.Errorf("%s: %s's share = %d, want %d", tt.name, share.Name, share.Amount.Amount, tt.want[i])
+			}
+			total += share.Amount.Amount
+		}
+		if total != tt.amount {
+			t.Errorf("%s: shares add up to %d, want %d", tt.name, total, tt.amount)
+		}
+	}
+}
+
+func TestValidateSharing(t *testing.T) {
+	sub := Subscription{Cost: NewMoney(1000, "USD"), Sharing: &Sharing{
+		Split: SplitFixed,
+		Participants: []Participant{
+			{Name: "Bob", Fixed: NewMoney(600, "USD")},
+			{Name: "Ann", Fixed: NewMoney(400, "USD")},
+		},
+	}}
+	if err := sub.ValidateSharing(); err != nil {
+		t.Errorf("shares equal to the cost: %v", err)
+	}
+
+	sub.Sharing.Participants[1].Fixed = NewMoney(401, "USD")
+	if err := sub.ValidateSharing(); !errors.Is(err, ErrSharesExceedCost) {
+		t.Errorf("shares over the cost: error = %v, want ErrSharesExceedCost", err)
+	}
+
+	// Percentages are limited when they are entered; only fixed amounts depend on the cost
+	sub.Sharing.Split = SplitEqual
+	if err := sub.ValidateSharing(); err != nil {
+		t.Errorf("equal split: %v", err)
+	}
+}
@@ -8,67 +8,102 @@ import (
 type BillingCycle string
 
 const (
-	Monthly BillingCycle = "monthly"
-	Yearly  BillingCycle = "yearly"
+	Weekly     BillingCycle = "weekly"
+	Monthly    BillingCycle = "monthly"
+	Quarterly  BillingCycle = "quarterly"
+	SemiAnnual BillingCycle = "semiannual"
+	Yearly     BillingCycle = "yearly"
+	Custom     BillingCycle = "custom" // Uses Subscription.CustomInterval
 )
 
-// Category represents subscription categories
+// Category identifies a subscription category in the category registry
+// The constants below are the built-in categories seeded into every registry
 type Category string
 
 const (
-	Streaming  Category = "streaming"
-	Software   Category = "software"
-	Utilities  Category = "utilities"
-	Gaming     Category = "gaming"
-	News       Category = "news"
-	Education  Category = "education"
-	Creator    Category = "creator"
-	Other      Category = "other"
+	Streaming Category = "streaming"
+	Software  Category = "software"
+	Utilities Category = "utilities"
+	Gaming    Category = "gaming"
+	News      Category = "news"
+	Education Category = "education"
+	Creator   Category = "creator"
+	Other     Category = "other"
 )
 
 // Subscription represents a single subscription
 type Subscription struct {
-	ID           string       `json:"id"`
-	Name         string       `json:"name"`
-	Cost         float64      `json:"cost"`
-	BillingCycle BillingCycle `json:"billing_cycle"`
-	NextPayment  time.Time    `json:"next_payment"`
-	StartDate    time.Time    `json:"start_date"`
-	Category     Category     `json:"category"`
-	Notes        string       `json:"notes"`
-	Image        string       `json:"image"` // Filename only (e.g., "abc-123.png"), stored in images/ folder
-	Paused       bool         `json:"paused"`
-	Deleted      bool         `json:"deleted"`      // Soft delete flag
-	DeletedAt    time.Time    `json:"deleted_at"`   // When subscription was deleted
-	CreatedAt    time.Time    `json:"created_at"`
-	UpdatedAt    time.Time    `json:"updated_at"`
+	ID              string           `json:"id"`
+	Name            string           `json:"name"`
+	Cost            Money            `json:"cost"`
+	BillingCycle    BillingCycle     `json:"billing_cycle"`
+	CustomInterval  *BillingInterval `json:"custom_interval,omitempty"` // Only used when BillingCycle is Custom
+	NextPayment     time.Time        `json:"next_payment"`
+	StartDate       time.Time        `json:"start_date"`
+	TrialEndDate    time.Time        `json:"trial_end_date"`          // Free trial ends (first charge) on this date; zero if no trial
+	PostTrialCost   Money            `json:"post_trial_cost"`         // Price charged once the trial converts
+	PriceHistory    []PriceChange    `json:"price_history,omitempty"` // Dated prices, oldest first
+	Category        Category         `json:"category"`
+	Tags            []string         `json:"tags,omitempty"`              // Free-form labels, normalized to lower case
+	PaymentMethodID string           `json:"payment_method_id,omitempty"` // Card or account billed; empty if unknown
+	Sharing         *Sharing         `json:"sharing,omitempty"`           // Participants splitting the cost; nil when not shared
+	Notes           string           `json:"notes"`
+	Image           string           `json:"image"` // Filename only (e.g., "abc-123.png"), stored in images/ folder
+	Paused          bool             `json:"paused"`
+	SkippedPayments []time.Time      `json:"skipped_payments,omitempty"` // Scheduled payments the user deleted; never regenerated
+	Pauses          []PausePeriod    `json:"pauses,omitempty"`           // Pause history, oldest first
+	CancelledAt     time.Time        `json:"cancelled_at"`               // No payments after this date; zero if not cancelled
+	AccessEndsAt    time.Time        `json:"access_ends_at"`             // Optional end of the already-paid period
+	Deleted         bool             `json:"deleted"`                    // Soft delete flag
+	DeletedAt       time.Time        `json:"deleted_at"`                 // When subscription was deleted
+	CreatedAt       time.Time        `json:"created_at"`
+	UpdatedAt       time.Time        `json:"updated_at"`
 }
 
 // Payment represents a single payment made for a subscription
 type Payment struct {
-	ID             string    `json:"id"`
-	SubscriptionID string    `json:"subscription_id"`
-	Amount         float64   `json:"amount"`
-	PaymentDate    time.Time `json:"payment_date"`
-	Notes          string    `json:"notes"`
-	CreatedAt      time.Time `json:"created_at"`
+	ID              string        `json:"id"`
+	SubscriptionID  string        `json:"subscription_id"`
+	Amount          Money         `json:"amount"` // Signed: refunds and credits are negative
+	Type            PaymentType   `json:"type"`
+	PaymentDate     time.Time     `json:"payment_date"`
+	Notes           string        `json:"notes"`
+	PaymentMethodID string        `json:"payment_method_id,omitempty"` // Card or account charged
+	Source          PaymentSource `json:"source"`
+	CreatedAt       time.Time     `json:"created_at"`
 }
 
 // SubscriptionList is a collection of subscriptions and payments
 type SubscriptionList struct {
-	Subscriptions []Subscription `json:"subscriptions"`
-	Payments      []Payment      `json:"payments"`
-	Version       string         `json:"version"`
+	Subscriptions   []Subscription  `json:"subscriptions"`
+	Payments        []Payment       `json:"payments"`
+	Categories      []CategoryDef   `json:"categories,omitempty"`
+	PaymentMethods  []PaymentMethod `json:"payment_methods,omitempty"`
+	Budgets         []Budget        `json:"budgets,omitempty"`
+	FiscalYearMonth time.Month      `json:"fiscal_year_start,omitempty"` // Month the fiscal year starts; unset means January
+	Version         string          `json:"version"`
+}
+
+// FiscalYearStart returns the month the fiscal year starts, January by default
+func (l *SubscriptionList) FiscalYearStart() time.Month {
+	if l.FiscalYearMonth < time.January || l.FiscalYearMonth > time.December {
+		return time.January
+	}
+	return l.FiscalYearMonth
 }
 
 // FilterCriteria defines search/filter parameters
 type FilterCriteria struct {
-	SearchTerm   string
-	Category     *Category
-	BillingCycle *BillingCycle
-	MinCost      *float64
-	MaxCost      *float64
-	ShowPaused   bool // If true, show paused subscriptions; if false, hide them
+	SearchTerm      string
+	Category        *Category
+	BillingCycle    *BillingCycle
+	MinCost         *float64
+	MaxCost         *float64
+	Tags            []string // Only subscriptions carrying these tags
+	PaymentMethodID *string  // Only subscriptions billed to this payment method
+	TagMatch        TagMatch // Whether any or all of Tags must be present; defaults to any
+	ShowPaused      bool     // If true, show paused subscriptions; if false, hide them
+	ShowCancelled   bool     // If true, show cancelled subscriptions; if false, hide them
 }
 
 // SortField defines sortable fields
@@ -89,11 +124,24 @@ const (
 )
 
 // CostSummary represents aggregated cost statistics
+// All amounts are converted to the base currency of the exchange-rate table
 type CostSummary struct {
-	TotalMonthly float64
-	TotalYearly  float64
-	YearToDate   float64 // Actual payments made from Jan 1 to today
-	ByCategory   map[Category]float64
-	Count        int // Total count of active (non-paused, non-deleted) subscriptions
-	PausedCount  int // Count of paused subscriptions
+	Currency       string    // Base currency the totals are expressed in
+	TotalMonthly   Money     // Sum of each subscription's rounded monthly equivalent
+	TotalYearly    Money     // Sum of each subscription's yearly equivalent (not TotalMonthly * 12)
+	YearStart      time.Time // First day of the current fiscal year
+	YearToDate     Money     // Actual payments made from YearStart to today
+	ByCategory     map[Category]Money
+	ByTag          map[string]Money // Monthly cost of active subscriptions per tag; a subscription counts towards each of its tags
+	MyMonthly      Money            // My share of TotalMonthly once shared subscriptions are split
+	SharedCount    int              // Count of active subscriptions split with other people
+	Count          int              // Total count of active (non-paused, non-deleted) subscriptions
+	PausedCount    int              // Count of paused subscriptions
+	CancelledCount int              // Count of cancelled subscriptions
+	SavedMonthly   Money            // Monthly cost no longer paid thanks to cancellations
+	SavedTotal     Money            // Charges avoided since each cancellation up to today
+	TrialCount     int              // Count of subscriptions still in their free trial
+	TrialsEnding   int              // Count of trials converting to paid within the next week
+	TrialMonthly   Money            // Monthly cost added once current trials convert
+	MissingRates   []string         // Currencies with no exchange rate (counted 1:1)
 }
@@ -0,0 +1,70 @@
+package models
+
+import (
+	"sort"
+	"strings"
+)
+
+// TagMatch decides how FilterCriteria.Tags are combined
+type TagMatch string
+
+const (
+	MatchAnyTag  TagMatch = "any" // Subscription has at least one of the tags
+	MatchAllTags TagMatch = "all" // Subscription has every tag
+)
+
+// NormalizeTag trims and lower-cases a tag so "Family " and "family" are the same tag
+func NormalizeTag(tag string) string {
+	return strings.ToLower(strings.TrimSpace(tag))
+}
+
+// ParseTags splits comma-separated text into normalized, de-duplicated tags
+func ParseTags(text string) []string {
+	return NormalizeTags(strings.Split(text, ","))
+}
+
+// NormalizeTags normalizes tags, dropping blanks and duplicates and sorting them
+func NormalizeTags(tags []string) []string {
+	seen := make(map[string]bool)
+	var result []string
+	for _, tag := range tags {
+		tag = NormalizeTag(tag)
+		if tag == "" || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		result = append(result, tag)
+	}
+	sort.Strings(result)
+	return result
+}
+
+// HasTag reports whether the subscription carries the tag
+func (s Subscription) HasTag(tag string) bool {
+	tag = NormalizeTag(tag)
+	for _, t := range s.Tags {
+		if NormalizeTag(t) == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// MatchesTags reports whether the subscription matches the tags under the given semantics
+// An empty tag list matches everything
+func (s Subscription) MatchesTags(tags []string, match TagMatch) bool {
+	if len(tags) == 0 {
+		return true
+	}
+
+	for _, tag := range tags {
+		has := s.HasTag(tag)
+		if match == MatchAllTags && !has {
+			return false
+		}
+		if match != MatchAllTags && has {
+			return true
+		}
+	}
+	return match == MatchAllTags
+}
@@ -0,0 +1,211 @@
+package service
+
+import (
+	"errors"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"subman/internal/models"
+	"subman/internal/storage"
+	"subman/pkg/calculator"
+)
+
+var (
+	ErrBudgetNotFound = errors.New("budget not found")
+	ErrBudgetExists   = errors.New("a budget for that already exists")
+	ErrBudgetLimit    = errors.New("budget limit must be more than zero")
+	ErrBudgetTarget   = errors.New("budget needs a category or tag")
+)
+
+var budgetScopeOrder = map[models.BudgetScope]int{
+	models.BudgetOverall:  0,
+	models.BudgetCategory: 1,
+	models.BudgetTag:      2,
+}
+
+type BudgetService struct {
+	storage storage.Storage
+	rates   *storage.RatesStorage
+}
+
+func NewBudgetService(storage storage.Storage, rates *storage.RatesStorage) *BudgetService {
+	return &BudgetService{
+		storage: storage,
+		rates:   rates,
+	}
+}
+
+// List returns all budgets: the overall budget first, then category and tag budgets
+func (b *BudgetService) List() ([]models.Budget, error) {
+	list, err := b.storage.Load()
+	if err != nil {
+		return nil, err
+	}
+
+	budgets := append([]models.Budget(nil), list.Budgets...)
+	sortBudgets(budgets)
+	return budgets, nil
+}
+
+// Create adds a budget; there can be one per category, one per tag and one overall
+func (b *BudgetService) Create(budget *models.Budget) error {
+	if err := validateBudget(budget); err != nil {
+		return err
+	}
+
+	list, err := b.storage.Load()
+	if err != nil {
+		return err
+	}
+
+	if _, exists := list.FindBudget(budget.Scope, budget.Target); exists {
+		return ErrBudgetExists
+	}
+
+	budget.ID = uuid.New().String()
+	budget.CreatedAt = time.Now()
+	budget.UpdatedAt = time.Now()
+
+	list.Budgets = append(list.Budgets, *budget)
+	return b.storage.Save(list)
+}
+
+// Update modifies an existing budget
+func (b *BudgetService) Update(budget *models.Budget) error {
+	if err := validateBudget(budget); err != nil {
+		return err
+	}
+
+	list, err := b.storage.Load()
+	if err != nil {
+		return err
+	}
+
+	if other, exists := list.FindBudget(budget.Scope, budget.Target); exists && other.ID != budget.ID {
+		return ErrBudgetExists
+	}
+
+	for i, existing := range list.Budgets {
+		if existing.ID == budget.ID {
+			budget.CreatedAt = existing.CreatedAt
+			budget.UpdatedAt = time.Now()
+			list.Budgets[i] = *budget
+			return b.storage.Save(list)
+		}
+	}
+
+	return ErrBudgetNotFound
+}
+
+// Delete removes a budget
+func (b *BudgetService) Delete(id string) error {
+	list, err := b.storage.Load()
+	if err != nil {
+		return err
+	}
+
+	for i, budget := range list.Budgets {
+		if budget.ID == id {
+			list.Budgets = append(list.Budgets[:i], list.Budgets[i+1:]...)
+			return b.storage.Save(list)
+		}
+	}
+
+	return ErrBudgetNotFound
+}
+
+// Status returns the utilization of every budget
+func (b *BudgetService) Status() ([]calculator.BudgetStatus, error) {
+	list, err := b.storage.Load()
+	if err != nil {
+		return nil, err
+	}
+
+	return b.status(list)
+}
+
+// CheckSubscription returns the budgets that saving sub would push (further)
+// over their limit, without saving anything
+func (b *BudgetService) CheckSubscription(sub *models.Subscription) ([]calculator.BudgetStatus, error) {
+	list, err := b.storage.Load()
+	if err != nil {
+		return nil, err
+	}
+	if len(list.Budgets) == 0 {
+		return nil, nil
+	}
+
+	before, err := b.status(list)
+	if err != nil {
+		return nil, err
+	}
+
+	list.Subscriptions = append([]models.Subscription(nil), list.Subscriptions...)
+	if existing := findSubscription(list, sub.ID); sub.ID != "" && existing != nil {
+		*existing = *sub
+	} else {
+		list.Subscriptions = append(list.Subscriptions, *sub)
+	}
+
+	after, err := b.status(list)
+	if err != nil {
+		return nil, err
+	}
+
+	var exceeded []calculator.BudgetStatus
+	for i, status := range after {
+		if status.Planned.Amount > status.Limit.Amount && status.Planned.Amount > before[i].Planned.Amount {
+			exceeded = append(exceeded, status)
+		}
+	}
+	return exceeded, nil
+}
+
+func (b *BudgetService) status(list *models.SubscriptionList) ([]calculator.BudgetStatus, error) {
+	rates, err := b.rates.Load()
+	if err != nil {
+		return nil, err
+	}
+
+	budgets := append([]models.Budget(nil), list.Budgets...)
+	sortBudgets(budgets)
+
+	now := time.Now()
+	summary := calculator.CalculateSummary(list.Subscriptions, list.Payments, rates, list.FiscalYearStart())
+	return calculator.CalculateBudgets(budgets, summary, list.Subscriptions, list.Payments, rates, now), nil
+}
+
+func sortBudgets(budgets []models.Budget) {
+	sort.SliceStable(budgets, func(i, j int) bool {
+		if budgets[i].Scope != budgets[j].Scope {
+			return budgetScopeOrder[budgets[i].Scope] < budgetScopeOrder[budgets[j].Scope]
+		}
+		return budgets[i].Target < budgets[j].Target
+	})
+}
+
+func validateBudget(budget *models.Budget) error {
+	if budget.Scope == "" {
+		budget.Scope = models.BudgetOverall
+	}
+
+	switch budget.Scope {
+	case models.BudgetOverall:
+		budget.Target = ""
+	case models.BudgetTag:
+		budget.Target = models.NormalizeTag(budget.Target)
+	default:
+		budget.Target = strings.TrimSpace(budget.Target)
+	}
+	if budget.Scope != models.BudgetOverall && budget.Target == "" {
+		return ErrBudgetTarget
+	}
+
+	if budget.Limit.Amount <= 0 {
+		return ErrBudgetLimit
+	}
+
+	return nil
+}
@@ -0,0 +1,178 @@
+package service
+
+import (
+	"errors"
+	"strings"
+
+	"subman/internal/images"
+	"subman/internal/models"
+	"subman/internal/storage"
+)
+
+var (
+	ErrCategoryNotFound = errors.New("category not found")
+	ErrCategoryExists   = errors.New("a category with that name already exists")
+	ErrCategoryName     = errors.New("category name is required")
+)
+
+type CategoryService struct {
+	storage storage.Storage
+}
+
+func NewCategoryService(storage storage.Storage) *CategoryService {
+	return &CategoryService{
+		storage: storage,
+	}
+}
+
+// Migrate seeds the category registry for older data files and registers any
+// category used by a subscription but missing from the registry
+func (c *CategoryService) Migrate() error {
+	list, err := c.storage.Load()
+	if err != nil {
+		return err
+	}
+
+	if !list.EnsureCategories() {
+		return nil
+	}
+
+	return c.storage.Save(list)
+}
+
+// List returns the category registry in display order
+func (c *CategoryService) List() ([]models.CategoryDef, error) {
+	list, err := c.storage.Load()
+	if err != nil {
+		return nil, err
+	}
+
+	list.EnsureCategories()
+	return list.Categories, nil
+}
+
+// Get retrieves a category by ID
+func (c *CategoryService) Get(id models.Category) (*models.CategoryDef, error) {
+	list, err := c.storage.Load()
+	if err != nil {
+		return nil, err
+	}
+
+	list.EnsureCategories()
+	def, ok := list.FindCategory(id)
+	if !ok {
+		return nil, ErrCategoryNotFound
+	}
+
+	return &def, nil
+}
+
+// Create adds a category, deriving its ID from the name
+func (c *CategoryService) Create(def *models.CategoryDef) error {
+	def.Name = strings.TrimSpace(def.Name)
+	def.ID = models.CategoryID(def.Name)
+	if def.ID == "" {
+		return ErrCategoryName
+	}
+	if def.Color == "" {
+		def.Color = models.DefaultCategoryColor
+	}
+
+	list, err := c.storage.Load()
+	if err != nil {
+		return err
+	}
+
+	list.EnsureCategories()
+	for _, existing := range list.Categories {
+		if existing.ID == def.ID || strings.EqualFold(existing.Name, def.Name) {
+			return ErrCategoryExists
+		}
+	}
+
+	list.Categories = append(list.Categories, *def)
+	return c.storage.Save(list)
+}
+
+// Update changes a category's name, colour or icon; its ID never changes
+func (c *CategoryService) Update(def *models.CategoryDef) error {
+	def.Name = strings.TrimSpace(def.Name)
+	if def.Name == "" {
+		return ErrCategoryName
+	}
+
+	list, err := c.storage.Load()
+	if err != nil {
+		return err
+	}
+
+	list.EnsureCategories()
+	found := false
+	for i, existing := range list.Categories {
+		if existing.ID != def.ID && strings.EqualFold(existing.Name, def.Name) {
+			return ErrCategoryExists
+		}
+		if existing.ID == def.ID {
+			list.Categories[i] = *def
+			found = true
+		}
+	}
+
+	if !found {
+		return ErrCategoryNotFound
+	}
+
+	return c.storage.Save(list)
+}
+
+// Delete removes a category and moves its subscriptions (including deleted ones)
+// to the replacement category; a budget for the category is removed with it
+func (c *CategoryService) Delete(id models.Category, replacement models.Category) error {
+	if id == replacement {
+		return errors.New("choose a different category to move subscriptions to")
+	}
+
+	list, err := c.storage.Load()
+	if err != nil {
+		return err
+	}
+
+	list.EnsureCategories()
+	if _, ok := list.FindCategory(replacement); !ok {
+		return ErrCategoryNotFound
+	}
+
+	kept := list.Categories[:0]
+	found := false
+	for _, def := range list.Categories {
+		if def.ID == id {
+			found = true
+			continue
+		}
+		kept = append(kept, def)
+	}
+	if !found {
+		return ErrCategoryNotFound
+	}
+	list.Categories = kept
+
+	for i, sub := range list.Subscriptions {
+		if sub.Category == id {
+			list.Subscriptions[i].Category = replacement
+			if sub.Image == images.GetDefaultImageForCategory(id) {
+				list.Subscriptions[i].Image = images.GetDefaultImageForCategory(replacement)
+			}
+		}
+	}
+
+	// A budget for the removed category has nothing left to limit
+	budgets := list.Budgets[:0]
+	for _, budget := range list.Budgets {
+		if budget.Scope != models.BudgetCategory || budget.Target != string(id) {
+			budgets = append(budgets, budget)
+		}
+	}
+	list.Budgets = budgets
+
+	return c.storage.Save(list)
+}
@@ -0,0 +1,176 @@
+package service
+
+import (
+	"errors"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"subman/internal/models"
+	"subman/internal/storage"
+)
+
+// CardExpiryWarningDays is how far ahead an expiring payment method is flagged
+const CardExpiryWarningDays = 60
+
+var (
+	ErrPaymentMethodNotFound = errors.New("payment method not found")
+	ErrPaymentMethodLabel    = errors.New("payment method label is required")
+)
+
+// ExpiringPaymentMethod is a payment method about to expire and the
+// subscriptions still billed to it
+type ExpiringPaymentMethod struct {
+	Method        models.PaymentMethod
+	Subscriptions []models.Subscription
+}
+
+type PaymentMethodService struct {
+	storage storage.Storage
+}
+
+func NewPaymentMethodService(storage storage.Storage) *PaymentMethodService {
+	return &PaymentMethodService{
+		storage: storage,
+	}
+}
+
+// List returns all payment methods sorted by label
+func (m *PaymentMethodService) List() ([]models.PaymentMethod, error) {
+	list, err := m.storage.Load()
+	if err != nil {
+		return nil, err
+	}
+
+	methods := append([]models.PaymentMethod(nil), list.PaymentMethods...)
+	sort.Slice(methods, func(i, j int) bool {
+		return strings.ToLower(methods[i].Label) < strings.ToLower(methods[j].Label)
+	})
+
+	return methods, nil
+}
+
+// Create adds a payment method
+func (m *PaymentMethodService) Create(method *models.PaymentMethod) error {
+	if err := validatePaymentMethod(method); err != nil {
+		return err
+	}
+
+	method.ID = uuid.New().String()
+	method.CreatedAt = time.Now()
+	method.UpdatedAt = time.Now()
+
+	list, err := m.storage.Load()
+	if err != nil {
+		return err
+	}
+
+	list.PaymentMethods = append(list.PaymentMethods, *method)
+	return m.storage.Save(list)
+}
+
+// Update modifies an existing payment method
+func (m *PaymentMethodService) Update(method *models.PaymentMethod) error {
+	if err := validatePaymentMethod(method); err != nil {
+		return err
+	}
+
+	list, err := m.storage.Load()
+	if err != nil {
+		return err
+	}
+
+	for i, existing := range list.PaymentMethods {
+		if existing.ID == method.ID {
+			method.CreatedAt = existing.CreatedAt
+			method.UpdatedAt = time.Now()
+			list.PaymentMethods[i] = *method
+			return m.storage.Save(list)
+		}
+	}
+
+	return ErrPaymentMethodNotFound
+}
+
+// Delete removes a payment method and unlinks the subscriptions billed to it
+// Past payments keep their reference as a record of how they were paid
+func (m *PaymentMethodService) Delete(id string) error {
+	list, err := m.storage.Load()
+	if err != nil {
+		return err
+	}
+
+	kept := list.PaymentMethods[:0]
+	for _, method := range list.PaymentMethods {
+		if method.ID != id {
+			kept = append(kept, method)
+		}
+	}
+	if len(kept) == len(list.PaymentMethods) {
+		return ErrPaymentMethodNotFound
+	}
+	list.PaymentMethods = kept
+
+	for i, sub := range list.Subscriptions {
+		if sub.PaymentMethodID == id {
+			list.Subscriptions[i].PaymentMethodID = ""
+			list.Subscriptions[i].UpdatedAt = time.Now()
+		}
+	}
+
+	return m.storage.Save(list)
+}
+
+// ExpiringSoon returns payment methods expiring within CardExpiryWarningDays
+// that still have active (not deleted or cancelled) subscriptions billed to them
+func (m *PaymentMethodService) ExpiringSoon() ([]ExpiringPaymentMethod, error) {
+	list, err := m.storage.Load()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	var expiring []ExpiringPaymentMethod
+	for _, method := range list.PaymentMethods {
+		if !method.ExpiresWithin(now, CardExpiryWarningDays) {
+			continue
+		}
+
+		entry := ExpiringPaymentMethod{Method: method}
+		for _, sub := range list.Subscriptions {
+			if sub.PaymentMethodID == method.ID && !sub.Deleted && !sub.IsCancelled() {
+				entry.Subscriptions = append(entry.Subscriptions, sub)
+			}
+		}
+		if len(entry.Subscriptions) > 0 {
+			expiring = append(expiring, entry)
+		}
+	}
+
+	sort.Slice(expiring, func(i, j int) bool {
+		return expiring[i].Method.Expires().Before(expiring[j].Method.Expires())
+	})
+
+	return expiring, nil
+}
+
+func validatePaymentMethod(method *models.PaymentMethod) error {
+	method.Label = strings.TrimSpace(method.Label)
+	if method.Label == "" {
+		return ErrPaymentMethodLabel
+	}
+
+	method.LastFour = strings.TrimSpace(method.LastFour)
+	if method.LastFour != "" {
+		if len(method.LastFour) != 4 || strings.Trim(method.LastFour, "0123456789") != "" {
+			return errors.New("last four digits must be exactly 4 digits")
+		}
+	}
+
+	if method.Type == "" {
+		method.Type = models.OtherMethod
+	}
+
+	return nil
+}
@@ -1,103 +1,99 @@
 package service
 
 import (
+	"errors"
+	"sort"
 	"time"
 
 	"github.com/google/uuid"
 	"subman/internal/models"
 	"subman/internal/storage"
+	"subman/pkg/calculator"
+)
+
+var (
+	ErrPaymentNotFound = errors.New("payment not found")
+	ErrInvalidPayment  = errors.New("payment needs a subscription and a date")
 )
 
 type PaymentService struct {
 	storage storage.Storage
+	rates   *storage.RatesStorage
 }
 
-func NewPaymentService(storage storage.Storage) *PaymentService {
+func NewPaymentService(storage storage.Storage, rates *storage.RatesStorage) *PaymentService {
 	return &PaymentService{
 		storage: storage,
+		rates:   rates,
 	}
 }
 
 // GeneratePaymentsForSubscription creates payment records for a subscription
 // based on its billing cycle and start date up to the current date
+// Every billing date is checked against the schedule, so a date already covered
+// by a payment (generated or manual) or deleted by the user is left alone.
+// Generated payments that are no longer on the schedule, because its start
+// date, trial or billing cycle changed, are removed; manual payments are kept.
 func (p *PaymentService) GeneratePaymentsForSubscription(sub *models.Subscription) error {
 	list, err := p.storage.Load()
 	if err != nil {
 		return err
 	}
 
-	// Don't generate payments for paused or deleted subscriptions
-	if sub.Paused || sub.Deleted {
+	// Don't generate payments for deleted subscriptions, or paused ones without
+	// pause dates (older records) since we can't tell which periods to skip
+	if sub.Deleted || (sub.Paused && len(sub.Pauses) == 0) {
 		return nil
 	}
 
-	// Get existing payments for this subscription
-	existingPayments := p.getPaymentsForSubscription(list.Payments, sub.ID)
+	now := time.Now()
+	interval := sub.Interval()
 
-	// Find the last payment date
-	lastPaymentDate := sub.StartDate
-	if len(existingPayments) > 0 {
-		// Find most recent payment
-		for _, payment := range existingPayments {
-			if payment.PaymentDate.After(lastPaymentDate) {
-				lastPaymentDate = payment.PaymentDate
-			}
-		}
+	// The first charge is one billing period after the start date, or on the
+	// day a free trial ends: nothing is charged during the trial
+	currentDate := interval.Next(sub.StartDate)
+	if sub.HasTrial() && sub.TrialEndDate.After(sub.StartDate) {
+		currentDate = sub.TrialEndDate
 	}
 
-	// Generate payments from last payment date to now
-	now := time.Now()
-	currentDate := lastPaymentDate
-
-	for currentDate.Before(now) {
-		// Calculate next payment date
-		if sub.BillingCycle == models.Monthly {
-			currentDate = currentDate.AddDate(0, 1, 0)
-		} else {
-			currentDate = currentDate.AddDate(1, 0, 0)
-		}
-
-		// Only create payment if it's in the past
-		if currentDate.Before(now) || currentDate.Equal(now.Truncate(24*time.Hour)) {
-			// Check if payment already exists for this date
-			if !p.paymentExistsForDate(list.Payments, sub.ID, currentDate) {
-				payment := models.Payment{
-					ID:             uuid.New().String(),
-					SubscriptionID: sub.ID,
-					Amount:         sub.Cost,
-					PaymentDate:    currentDate,
-					Notes:          "Auto-generated",
-					CreatedAt:      time.Now(),
-				}
-				list.Payments = append(list.Payments, payment)
-			}
+	var billingDates []time.Time
+	for ; currentDate.Before(now); currentDate = interval.Next(currentDate) {
+		// Nothing is billed after a cancellation
+		if sub.IsCancelled() && currentDate.After(sub.CancelledAt) {
+			break
 		}
+		billingDates = append(billingDates, currentDate)
 	}
+	list.Payments = dropUnscheduledPayments(list.Payments, sub.ID, billingDates)
 
-	// Calculate and update the next payment date
-	nextPaymentDate := currentDate
-	if !nextPaymentDate.After(now) {
-		// If currentDate is not after now, calculate the next billing cycle
-		if sub.BillingCycle == models.Monthly {
-			nextPaymentDate = currentDate.AddDate(0, 1, 0)
-		} else {
-			nextPaymentDate = currentDate.AddDate(1, 0, 0)
+	for _, date := range billingDates {
+		// Nothing is billed while the subscription is paused, and payments the
+		// user deleted stay deleted
+		if sub.PausedAt(date) || sub.IsSkippedPayment(date) {
+			continue
 		}
-	}
 
-	// Update the subscription's NextPayment field
-	for i := range list.Subscriptions {
-		if list.Subscriptions[i].ID == sub.ID {
-			list.Subscriptions[i].NextPayment = nextPaymentDate
-			list.Subscriptions[i].UpdatedAt = time.Now()
-			break
+		if !p.paymentExistsForDate(list.Payments, sub.ID, date) {
+			list.Payments = append(list.Payments, p.newGeneratedPayment(sub, date))
 		}
 	}
 
-	return p.storage.Save(list)
+	// A cancelled subscription keeps its history but has no next payment
+	if sub.IsCancelled() {
+		return p.storage.Save(list)
+	}
+
+	// Skip billing dates that fall in a pause with a known resume date
+	nextPaymentDate := currentDate
+	for sub.PausedAt(nextPaymentDate) && !sub.PausedUntil().IsZero() {
+		nextPaymentDate = interval.Next(nextPaymentDate)
+	}
+
+	return p.updateNextPayment(list, sub.ID, nextPaymentDate)
 }
 
-// GenerateAllPayments generates payments for all active subscriptions
+// GenerateAllPayments generates payments for all non-deleted subscriptions
+// Paused periods are skipped per subscription
 func (p *PaymentService) GenerateAllPayments() error {
 	list, err := p.storage.Load()
 	if err != nil {
@@ -105,7 +101,7 @@ func (p *PaymentService) GenerateAllPayments() error {
 	}
 
 	for _, sub := range list.Subscriptions {
-		if !sub.Paused && !sub.Deleted {
+		if !sub.Deleted {
 			if err := p.GeneratePaymentsForSubscription(&sub); err != nil {
 				return err
 			}
@@ -115,40 +111,239 @@ func (p *PaymentService) GenerateAllPayments() error {
 	return nil
 }
 
-// GetPaymentsForSubscription returns all payments for a subscription
+// GetPaymentsForSubscription returns all payments for a subscription, newest first
 func (p *PaymentService) GetPaymentsForSubscription(subscriptionID string) ([]models.Payment, error) {
 	list, err := p.storage.Load()
 	if err != nil {
 		return nil, err
 	}
 
-	return p.getPaymentsForSubscription(list.Payments, subscriptionID), nil
+	payments := p.getPaymentsForSubscription(list.Payments, subscriptionID)
+	sort.Slice(payments, func(i, j int) bool {
+		return payments[i].PaymentDate.After(payments[j].PaymentDate)
+	})
+
+	return payments, nil
 }
 
-// GetYTDPayments returns all payments from January 1 of current year to today
+// GetPayment retrieves a payment by ID
+func (p *PaymentService) GetPayment(id string) (*models.Payment, error) {
+	list, err := p.storage.Load()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, payment := range list.Payments {
+		if payment.ID == id {
+			return &payment, nil
+		}
+	}
+
+	return nil, ErrPaymentNotFound
+}
+
+// AddPayment records a manually entered payment, such as a one-off charge
+// The subscription's payment method is used when none is given
+func (p *PaymentService) AddPayment(payment *models.Payment) error {
+	if payment.SubscriptionID == "" || payment.PaymentDate.IsZero() {
+		return ErrInvalidPayment
+	}
+
+	list, err := p.storage.Load()
+	if err != nil {
+		return err
+	}
+
+	sub := findSubscription(list, payment.SubscriptionID)
+	if sub == nil {
+		return ErrSubscriptionNotFound
+	}
+
+	payment.ID = uuid.New().String()
+	payment.Source = models.SourceManual
+	normalizePaymentType(payment)
+	payment.CreatedAt = time.Now()
+	if payment.PaymentMethodID == "" {
+		payment.PaymentMethodID = sub.PaymentMethodID
+	}
+
+	list.Payments = append(list.Payments, *payment)
+	return p.storage.Save(list)
+}
+
+// UpdatePayment corrects an existing payment
+// An edited payment becomes manual so regeneration never overwrites it; if a
+// generated payment is moved to another date, its original billing date is not
+// generated again.
+func (p *PaymentService) UpdatePayment(payment *models.Payment) error {
+	if payment.PaymentDate.IsZero() {
+		return ErrInvalidPayment
+	}
+
+	list, err := p.storage.Load()
+	if err != nil {
+		return err
+	}
+
+	for i, existing := range list.Payments {
+		if existing.ID != payment.ID {
+			continue
+		}
+
+		if existing.IsGenerated() && !models.SameDay(existing.PaymentDate, payment.PaymentDate) {
+			if sub := findSubscription(list, existing.SubscriptionID); sub != nil {
+				sub.SkipPayment(existing.PaymentDate)
+			}
+		}
+
+		payment.SubscriptionID = existing.SubscriptionID
+		payment.CreatedAt = existing.CreatedAt
+		payment.Source = models.SourceManual
+		normalizePaymentType(payment)
+		list.Payments[i] = *payment
+		return p.storage.Save(list)
+	}
+
+	return ErrPaymentNotFound
+}
+
+// ConfirmPayment marks a generated payment as checked by the user, protecting
+// it from being re-priced or regenerated
+func (p *PaymentService) ConfirmPayment(id string) error {
+	list, err := p.storage.Load()
+	if err != nil {
+		return err
+	}
+
+	for i, payment := range list.Payments {
+		if payment.ID == id {
+			list.Payments[i].Source = models.SourceManual
+			return p.storage.Save(list)
+		}
+	}
+
+	return ErrPaymentNotFound
+}
+
+// DeletePayment removes a payment
+// A deleted generated payment is remembered so regeneration doesn't bring it back
+func (p *PaymentService) DeletePayment(id string) error {
+	list, err := p.storage.Load()
+	if err != nil {
+		return err
+	}
+
+	for i, payment := range list.Payments {
+		if payment.ID != id {
+			continue
+		}
+
+		if payment.IsGenerated() {
+			if sub := findSubscription(list, payment.SubscriptionID); sub != nil {
+				sub.SkipPayment(payment.PaymentDate)
+			}
+		}
+
+		list.Payments = append(list.Payments[:i], list.Payments[i+1:]...)
+		return p.storage.Save(list)
+	}
+
+	return ErrPaymentNotFound
+}
+
+// GetYTDPayments returns all payments from the start of the fiscal year to today
+// that moved money: charges, refunds and credits, but not failed or pending ones
 func (p *PaymentService) GetYTDPayments() ([]models.Payment, error) {
 	list, err := p.storage.Load()
 	if err != nil {
 		return nil, err
 	}
+	return p.GetPaymentsInRange(calculator.FiscalYearToDate(time.Now(), list.FiscalYearStart()))
+}
 
-	now := time.Now()
-	startOfYear := time.Date(now.Year(), 1, 1, 0, 0, 0, 0, now.Location())
+// GetPaymentsInRange returns the settled payments made within the range, oldest first
+func (p *PaymentService) GetPaymentsInRange(r calculator.DateRange) ([]models.Payment, error) {
+	list, err := p.storage.Load()
+	if err != nil {
+		return nil, err
+	}
 
-	var ytdPayments []models.Payment
+	var payments []models.Payment
 	for _, payment := range list.Payments {
-		if payment.PaymentDate.After(startOfYear) || payment.PaymentDate.Equal(startOfYear) {
-			if payment.PaymentDate.Before(now) || payment.PaymentDate.Equal(now.Truncate(24*time.Hour)) {
-				ytdPayments = append(ytdPayments, payment)
-			}
+		if payment.IsSettled() && r.Contains(payment.PaymentDate) {
+			payments = append(payments, payment)
 		}
 	}
 
-	return ytdPayments, nil
+	sort.Slice(payments, func(i, j int) bool {
+		return payments[i].PaymentDate.Before(payments[j].PaymentDate)
+	})
+
+	return payments, nil
+}
+
+// GetSpendingSummary totals what was paid within the range, by category and subscription
+func (p *PaymentService) GetSpendingSummary(r calculator.DateRange) (*calculator.SpendingSummary, error) {
+	list, err := p.storage.Load()
+	if err != nil {
+		return nil, err
+	}
+
+	rates, err := p.rates.Load()
+	if err != nil {
+		return nil, err
+	}
+
+	return calculator.SummarizeRange(list.Subscriptions, list.Payments, rates, r), nil
 }
 
 // Helper functions
 
+func (p *PaymentService) newGeneratedPayment(sub *models.Subscription, date time.Time) models.Payment {
+	return models.Payment{
+		ID:              uuid.New().String(),
+		SubscriptionID:  sub.ID,
+		Amount:          sub.PriceAt(date),
+		PaymentDate:     date,
+		Notes:           models.AutoGeneratedNote,
+		PaymentMethodID: sub.PaymentMethodID,
+		Type:            models.PaymentCharged,
+		Source:          models.SourceGenerated,
+		CreatedAt:       time.Now(),
+	}
+}
+
+// updateNextPayment stores the subscription's NextPayment field and saves the list
+func (p *PaymentService) updateNextPayment(list *models.SubscriptionList, subscriptionID string, next time.Time) error {
+	for i := range list.Subscriptions {
+		if list.Subscriptions[i].ID == subscriptionID {
+			list.Subscriptions[i].NextPayment = next
+			list.Subscriptions[i].UpdatedAt = time.Now()
+			break
+		}
+	}
+
+	return p.storage.Save(list)
+}
+
+// normalizePaymentType defaults the type to a charge and applies its sign
+func normalizePaymentType(payment *models.Payment) {
+	if payment.Type == "" {
+		payment.Type = models.PaymentCharged
+	}
+	payment.NormalizeSign()
+}
+
+// findSubscription returns a pointer into the list so changes are saved with it
+func findSubscription(list *models.SubscriptionList, id string) *models.Subscription {
+	for i := range list.Subscriptions {
+		if list.Subscriptions[i].ID == id {
+			return &list.Subscriptions[i]
+		}
+	}
+	return nil
+}
+
 func (p *PaymentService) getPaymentsForSubscription(payments []models.Payment, subscriptionID string) []models.Payment {
 	var result []models.Payment
 	for _, payment := range payments {
@@ -159,14 +354,33 @@ func (p *PaymentService) getPaymentsForSubscription(payments []models.Payment, s
 	return result
 }
 
+// dropUnscheduledPayments removes the subscription's generated payments that
+// don't fall on one of its billing dates
+func dropUnscheduledPayments(payments []models.Payment, subscriptionID string, billingDates []time.Time) []models.Payment {
+	kept := payments[:0]
+	for _, payment := range payments {
+		if payment.SubscriptionID == subscriptionID && payment.IsGenerated() && !onSchedule(payment.PaymentDate, billingDates) {
+			continue
+		}
+		kept = append(kept, payment)
+	}
+	return kept
+}
+
+// onSchedule reports whether date is one of the billing dates
+func onSchedule(date time.Time, billingDates []time.Time) bool {
+	for _, billing := range billingDates {
+		if models.SameDay(billing, date) {
+			return true
+		}
+	}
+	return false
+}
+
 func (p *PaymentService) paymentExistsForDate(payments []models.Payment, subscriptionID string, date time.Time) bool {
-	dateOnly := date.Truncate(24 * time.Hour)
 	for _, payment := range payments {
-		if payment.SubscriptionID == subscriptionID {
-			paymentDateOnly := payment.PaymentDate.Truncate(24 * time.Hour)
-			if paymentDateOnly.Equal(dateOnly) {
-				return true
-			}
+		if payment.SubscriptionID == subscriptionID && models.SameDay(payment.PaymentDate, date) {
+			return true
 		}
 	}
 	return false
@@ -0,0 +1,226 @@
+package service
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"subman/internal/models"
+	"subman/internal/storage"
+)
+
+// newTestServices returns services sharing a JSON storage in a fresh directory
+func newTestServices(t *testing.T) (*SubscriptionService, *PaymentService) {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "subscriptions.json")
+	store := storage.NewJSONStorageWithPath(path)
+	rates := storage.NewRatesStorage(path)
+	return NewSubscriptionService(store, rates), NewPaymentService(store, rates)
+}
+
+// createWeekly adds a weekly subscription whose last four billing dates are
+// 22, 15, 8 and 1 days ago
+func createWeekly(t *testing.T, subs *SubscriptionService) *models.Subscription {
+	t.Helper()
+
+	today := models.CalendarDay(time.Now().UTC())
+	sub := &models.Subscription{
+		Name:         "Weekly",
+		Cost:         models.NewMoney(500, "USD"),
+		BillingCycle: models.Weekly,
+		StartDate:    today.AddDate(0, 0, -29),
+	}
+	if err := subs.Create(sub); err != nil {
+		t.Fatal(err)
+	}
+	return sub
+}
+
+// generate regenerates the subscription's payments and returns them, oldest first
+func generate(t *testing.T, subs *SubscriptionService, payments *PaymentService, id string) []models.Payment {
+	t.Helper()
+
+	sub, err := subs.Get(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := payments.GeneratePaymentsForSubscription(sub); err != nil {
+		t.Fatalf("GeneratePaymentsForSubscription: %v", err)
+	}
+	list, err := payments.GetPaymentsForSubscription(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, j := 0, len(list)-1; i < j; i, j = i+1, j-1 {
+		list[i], list[j] = list[j], list[i]
+	}
+	return list
+}
+
+// checkDates compares payment dates with days relative to today
+func checkDates(t *testing.T, list []models.Payment, daysAgo ...int) {
+	t.Helper()
+
+	if len(list) != len(daysAgo) {
+		t.Fatalf("got %d payments, want %d", len(list), len(daysAgo))
+	}
+	today := models.CalendarDay(time.Now().UTC())
+	for i, days := range daysAgo {
+		if want := today.AddDate(0, 0, -days); !models.SameDay(list[i].PaymentDate, want) {
+			t.Errorf("payment %d on %s, want %s", i, list[i].PaymentDate.Format("2006-01-02"), want.Format("2006-01-02"))
+		}
+	}
+}
+
+func TestGeneratePayments(t *testing.T) {
+	subs, payments := newTestServices(t)
+	sub := createWeekly(t, subs)
+
+	list := generate(t, subs, payments, sub.ID)
+	checkDates(t, list, 22, 15, 8, 1)
+	for _, payment := range list {
+		if !payment.IsGenerated() || payment.Amount != models.NewMoney(500, "USD") {
+			t.Errorf("payment %v, want a generated charge of 5.00 USD", payment)
+		}
+	}
+
+	// Generating again adds nothing
+	checkDates(t, generate(t, subs, payments, sub.ID), 22, 15, 8, 1)
+
+	updated, _ := subs.Get(sub.ID)
+	today := models.CalendarDay(time.Now().UTC())
+	if want := today.AddDate(0, 0, 6); !models.SameDay(updated.NextPayment, want) {
+		t.Errorf("next payment = %s, want %s", updated.NextPayment.Format("2006-01-02"), want.Format("2006-01-02"))
+	}
+}
+
+func TestGeneratePaymentsAfterScheduleChange(t *testing.T) {
+	subs, payments := newTestServices(t)
+	sub := createWeekly(t, subs)
+	generate(t, subs, payments, sub.ID)
+
+	today := models.CalendarDay(time.Now().UTC())
+	manual := &models.Payment{
+		SubscriptionID: sub.ID,
+		Amount:         models.NewMoney(500, "USD"),
+		PaymentDate:    today.AddDate(0, 0, -15),
+	}
+	if err := payments.AddPayment(manual); err != nil {
+		t.Fatal(err)
+	}
+
+	// Moving the start date two days on moves every billing date with it
+	edited, _ := subs.Get(sub.ID)
+	edited.StartDate = edited.StartDate.AddDate(0, 0, 2)
+	if err := subs.Update(edited); err != nil {
+		t.Fatal(err)
+	}
+
+	list := generate(t, subs, payments, sub.ID)
+	checkDates(t, list, 20, 15, 13, 6)
+	if list[1].ID != manual.ID {
+		t.Error("manual payment was not kept")
+	}
+
+	// So does changing the billing cycle
+	edited, _ = subs.Get(sub.ID)
+	edited.BillingCycle = models.Monthly
+	edited.StartDate = today.AddDate(0, 0, -40)
+	if err := subs.Update(edited); err != nil {
+		t.Fatal(err)
+	}
+	list = generate(t, subs, payments, sub.ID)
+	if len(list) != 2 || list[0].ID != manual.ID || !list[1].IsGenerated() {
+		t.Fatalf("got %v, want the manual payment and one monthly charge", list)
+	}
+	if want := edited.StartDate.AddDate(0, 1, 0); !models.SameDay(list[1].PaymentDate, want) {
+		t.Errorf("monthly charge on %s, want %s", list[1].PaymentDate.Format("2006-01-02"), want.Format("2006-01-02"))
+	}
+}
+
+func TestAddPayment(t *testing.T) {
+	subs, payments := newTestServices(t)
+	sub := createWeekly(t, subs)
+
+	if err := payments.AddPayment(&models.Payment{SubscriptionID: sub.ID}); !errors.Is(err, ErrInvalidPayment) {
+		t.Errorf("AddPayment without a date: error = %v, want ErrInvalidPayment", err)
+	}
+	if err := payments.AddPayment(&models.Payment{SubscriptionID: "missing", PaymentDate: time.Now()}); !errors.Is(err, ErrSubscriptionNotFound) {
+		t.Errorf("AddPayment for a missing subscription: error = %v, want ErrSubscriptionNotFound", err)
+	}
+
+	// A manual payment on a billing date stands in for the generated one
+	today := models.CalendarDay(time.Now().UTC())
+	manual := &models.Payment{
+		SubscriptionID: sub.ID,
+		Amount:         models.NewMoney(450, "USD"),
+		PaymentDate:    today.AddDate(0, 0, -8),
+	}
+	if err := payments.AddPayment(manual); err != nil {
+		t.Fatal(err)
+	}
+	if manual.Source != models.SourceManual || manual.Type != models.PaymentCharged {
+		t.Errorf("added payment source %q, type %q", manual.Source, manual.Type)
+	}
+
+	list := generate(t, subs, payments, sub.ID)
+	checkDates(t, list, 22, 15, 8, 1)
+	if list[2].ID != manual.ID {
+		t.Error("generated a second payment on the manual payment's date")
+	}
+}
+
+func TestDeletePaymentSkipsBillingDate(t *testing.T) {
+	subs, payments := newTestServices(t)
+	sub := createWeekly(t, subs)
+	list := generate(t, subs, payments, sub.ID)
+
+	if err := payments.DeletePayment(list[1].ID); err != nil {
+		t.Fatal(err)
+	}
+	updated, _ := subs.Get(sub.ID)
+	if !updated.IsSkippedPayment(list[1].PaymentDate) {
+		t.Error("deleted payment's date was not skipped")
+	}
+
+	checkDates(t, generate(t, subs, payments, sub.ID), 22, 8, 1)
+
+	if err := payments.DeletePayment("missing"); !errors.Is(err, ErrPaymentNotFound) {
+		t.Errorf("DeletePayment of a missing payment: error = %v, want ErrPaymentNotFound", err)
+	}
+}
+
+func TestUpdatePaymentSkipsBillingDate(t *testing.T) {
+	subs, payments := newTestServices(t)
+	sub := createWeekly(t, subs)
+	list := generate(t, subs, payments, sub.ID)
+
+	// Correcting only the amount keeps the billing date in use
+	amended := list[0]
+	amended.Amount = models.NewMoney(550, "USD")
+	if err := payments.UpdatePayment(&amended); err != nil {
+		t.Fatal(err)
+	}
+	if amended.Source != models.SourceManual {
+		t.Errorf("edited payment source = %q, want manual", amended.Source)
+	}
+
+	// Moving a payment to another day skips its billing date
+	moved := list[1]
+	moved.PaymentDate = moved.PaymentDate.AddDate(0, 0, 3)
+	if err := payments.UpdatePayment(&moved); err != nil {
+		t.Fatal(err)
+	}
+
+	updated, _ := subs.Get(sub.ID)
+	if updated.IsSkippedPayment(list[0].PaymentDate) {
+		t.Error("payment edited in place was skipped")
+	}
+	if !updated.IsSkippedPayment(list[1].PaymentDate) {
+		t.Error("moved payment's billing date was not skipped")
+	}
+
+	checkDates(t, generate(t, subs, payments, sub.ID), 22, 12, 8, 1)
+}
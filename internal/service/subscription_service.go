@@ -2,6 +2,7 @@ package service
 
 import (
 	"errors"
+	"fmt"
 	"sort"
 	"strings"
 	"time"
@@ -13,22 +14,36 @@ import (
 )
 
 var (
-	ErrSubscriptionNotFound = errors.New("subscription not found")
-	ErrInvalidID            = errors.New("invalid subscription ID")
+	ErrSubscriptionNotFound   = errors.New("subscription not found")
+	ErrInvalidID              = errors.New("invalid subscription ID")
+	ErrInvalidFiscalYearStart = errors.New("fiscal year must start in a month from January to December")
+	ErrBackupsUnsupported     = errors.New("backups are only kept for JSON storage")
+	ErrEncryptionUnsupported  = errors.New("encryption is only available for JSON storage")
+	ErrPassphraseTooShort     = fmt.Errorf("passphrase must be at least %d characters", MinPassphraseLength)
+	ErrSubscriptionChanged    = errors.New("the subscription was changed elsewhere since it was opened")
 )
 
+// MinPassphraseLength is the shortest passphrase accepted for encryption
+const MinPassphraseLength = 8
+
 type SubscriptionService struct {
 	storage storage.Storage
+	rates   *storage.RatesStorage
 }
 
-func NewSubscriptionService(storage storage.Storage) *SubscriptionService {
+func NewSubscriptionService(storage storage.Storage, rates *storage.RatesStorage) *SubscriptionService {
 	return &SubscriptionService{
 		storage: storage,
+		rates:   rates,
 	}
 }
 
 // Create adds a new subscription
 func (s *SubscriptionService) Create(sub *models.Subscription) error {
+	if err := sub.ValidateSharing(); err != nil {
+		return err
+	}
+
 	sub.ID = uuid.New().String()
 	sub.CreatedAt = time.Now()
 	sub.UpdatedAt = time.Now()
@@ -43,10 +58,24 @@ func (s *SubscriptionService) Create(sub *models.Subscription) error {
 }
 
 // Update modifies an existing subscription
+// A changed price is recorded in the price history as effective today
 func (s *SubscriptionService) Update(sub *models.Subscription) error {
+	return s.UpdateEffective(sub, time.Now())
+}
+
+// UpdateEffective modifies an existing subscription, recording a changed price
+// as effective from the given date. Auto-generated payments on or after that
+// date are re-priced; manually entered payments are left alone.
+// If sub carries the UpdatedAt it was loaded with and the stored subscription
+// has been changed since, ErrSubscriptionChanged is returned instead of
+// overwriting that change.
+func (s *SubscriptionService) UpdateEffective(sub *models.Subscription, priceEffective time.Time) error {
 	if sub.ID == "" {
 		return ErrInvalidID
 	}
+	if err := sub.ValidateSharing(); err != nil {
+		return err
+	}
 
 	list, err := s.storage.Load()
 	if err != nil {
@@ -56,8 +85,22 @@ func (s *SubscriptionService) Update(sub *models.Subscription) error {
 	found := false
 	for i, existing := range list.Subscriptions {
 		if existing.ID == sub.ID {
+			if !sub.UpdatedAt.IsZero() && !sub.UpdatedAt.Equal(existing.UpdatedAt) {
+				return ErrSubscriptionChanged
+			}
 			sub.CreatedAt = existing.CreatedAt
 			sub.UpdatedAt = time.Now()
+
+			// Price history and skipped payments are owned by the service, not the caller
+			sub.PriceHistory = existing.PriceHistory
+			sub.SkippedPayments = existing.SkippedPayments
+			if sub.BilledCost() != existing.BilledCost() {
+				sub.RecordPriceChange(existing.BilledCost(), sub.BilledCost(), priceEffective)
+				s.repriceGeneratedPayments(list.Payments, sub, priceEffective)
+			}
+			// A change scheduled for a future date leaves the current price in force
+			sub.ApplyPriceSchedule(time.Now())
+
 			list.Subscriptions[i] = *sub
 			found = true
 			break
@@ -97,6 +140,182 @@ func (s *SubscriptionService) Delete(id string) error {
 	return s.storage.Save(list)
 }
 
+// ConvertEndedTrials turns subscriptions whose free trial has ended into
+// normal paid subscriptions charged at their post-trial price
+func (s *SubscriptionService) ConvertEndedTrials() error {
+	list, err := s.storage.Load()
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	converted := false
+	for i, sub := range list.Subscriptions {
+		if sub.HasTrial() && !sub.InTrial(now) && !sub.PostTrialCost.IsZero() && !sub.IsCancelled() {
+			list.Subscriptions[i].Cost = sub.PriceAt(now)
+			list.Subscriptions[i].PostTrialCost = models.Zero(sub.PostTrialCost.Currency)
+			list.Subscriptions[i].UpdatedAt = now
+			converted = true
+		}
+	}
+
+	if !converted {
+		return nil
+	}
+
+	return s.storage.Save(list)
+}
+
+// ApplyScheduledPrices moves every subscription's current price to the one
+// scheduled for today, e.g. when an introductory price ends
+func (s *SubscriptionService) ApplyScheduledPrices() error {
+	list, err := s.storage.Load()
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	changed := false
+	for i := range list.Subscriptions {
+		if list.Subscriptions[i].ApplyPriceSchedule(now) {
+			list.Subscriptions[i].UpdatedAt = now
+			changed = true
+		}
+	}
+
+	if !changed {
+		return nil
+	}
+
+	return s.storage.Save(list)
+}
+
+// ResumeDuePauses resumes paused subscriptions whose auto-resume date has passed
+func (s *SubscriptionService) ResumeDuePauses() error {
+	list, err := s.storage.Load()
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	resumed := false
+	for i, sub := range list.Subscriptions {
+		if sub.ResumeDue(now) {
+			// The pause keeps its resume date, so billing restarts from there
+			list.Subscriptions[i].Paused = false
+			list.Subscriptions[i].UpdatedAt = now
+			resumed = true
+		}
+	}
+
+	if !resumed {
+		return nil
+	}
+
+	return s.storage.Save(list)
+}
+
+// ListDeleted returns soft-deleted subscriptions, most recently deleted first
+func (s *SubscriptionService) ListDeleted() ([]models.Subscription, error) {
+	list, err := s.storage.Load()
+	if err != nil {
+		return nil, err
+	}
+
+	var deleted []models.Subscription
+	for _, sub := range list.Subscriptions {
+		if sub.Deleted {
+			deleted = append(deleted, sub)
+		}
+	}
+
+	sort.Slice(deleted, func(i, j int) bool {
+		return deleted[i].DeletedAt.After(deleted[j].DeletedAt)
+	})
+
+	return deleted, nil
+}
+
+// Restore brings a soft-deleted subscription back
+func (s *SubscriptionService) Restore(id string) error {
+	list, err := s.storage.Load()
+	if err != nil {
+		return err
+	}
+
+	for i, sub := range list.Subscriptions {
+		if sub.ID == id && sub.Deleted {
+			list.Subscriptions[i].Deleted = false
+			list.Subscriptions[i].DeletedAt = time.Time{}
+			list.Subscriptions[i].UpdatedAt = time.Now()
+			return s.storage.Save(list)
+		}
+	}
+
+	return ErrSubscriptionNotFound
+}
+
+// Purge permanently removes a soft-deleted subscription
+// If withPayments is true its payment history is removed as well
+func (s *SubscriptionService) Purge(id string, withPayments bool) error {
+	list, err := s.storage.Load()
+	if err != nil {
+		return err
+	}
+
+	if !s.purge(list, func(sub models.Subscription) bool { return sub.ID == id }, withPayments) {
+		return ErrSubscriptionNotFound
+	}
+
+	return s.storage.Save(list)
+}
+
+// PurgeDeletedBefore permanently removes subscriptions deleted before cutoff,
+// along with their payments, and returns how many were removed
+func (s *SubscriptionService) PurgeDeletedBefore(cutoff time.Time) (int, error) {
+	list, err := s.storage.Load()
+	if err != nil {
+		return 0, err
+	}
+
+	before := len(list.Subscriptions)
+	if !s.purge(list, func(sub models.Subscription) bool { return sub.DeletedAt.Before(cutoff) }, true) {
+		return 0, nil
+	}
+
+	return before - len(list.Subscriptions), s.storage.Save(list)
+}
+
+// purge drops deleted subscriptions matching the predicate and reports whether any were removed
+func (s *SubscriptionService) purge(list *models.SubscriptionList, match func(models.Subscription) bool, withPayments bool) bool {
+	purged := make(map[string]bool)
+	kept := list.Subscriptions[:0]
+	for _, sub := range list.Subscriptions {
+		if sub.Deleted && match(sub) {
+			purged[sub.ID] = true
+			continue
+		}
+		kept = append(kept, sub)
+	}
+	list.Subscriptions = kept
+
+	if len(purged) == 0 {
+		return false
+	}
+
+	if withPayments {
+		payments := list.Payments[:0]
+		for _, payment := range list.Payments {
+			if !purged[payment.SubscriptionID] {
+				payments = append(payments, payment)
+			}
+		}
+		list.Payments = payments
+	}
+
+	return true
+}
+
 // Get retrieves a subscription by ID
 func (s *SubscriptionService) Get(id string) (*models.Subscription, error) {
 	list, err := s.storage.Load()
@@ -120,15 +339,38 @@ func (s *SubscriptionService) List(filter *models.FilterCriteria, sortBy models.
 		return nil, err
 	}
 
+	// Costs are compared in the base currency
+	rates, err := s.rates.Load()
+	if err != nil {
+		return nil, err
+	}
+
 	// Apply filters
-	filtered := s.filterSubscriptions(list.Subscriptions, filter)
+	filtered := s.filterSubscriptions(list.Subscriptions, filter, rates)
 
 	// Apply sorting
-	s.sortSubscriptions(filtered, sortBy, order)
+	s.sortSubscriptions(filtered, sortBy, order, rates)
 
 	return filtered, nil
 }
 
+// ListTags returns every tag used by a subscription that isn't deleted, sorted
+func (s *SubscriptionService) ListTags() ([]string, error) {
+	list, err := s.storage.Load()
+	if err != nil {
+		return nil, err
+	}
+
+	var tags []string
+	for _, sub := range list.Subscriptions {
+		if !sub.Deleted {
+			tags = append(tags, sub.Tags...)
+		}
+	}
+
+	return models.NormalizeTags(tags), nil
+}
+
 // GetSummary calculates cost statistics including YTD from payment history
 func (s *SubscriptionService) GetSummary() (*models.CostSummary, error) {
 	list, err := s.storage.Load()
@@ -136,7 +378,167 @@ func (s *SubscriptionService) GetSummary() (*models.CostSummary, error) {
 		return nil, err
 	}
 
-	return calculator.CalculateSummary(list.Subscriptions, list.Payments), nil
+	rates, err := s.rates.Load()
+	if err != nil {
+		return nil, err
+	}
+
+	return calculator.CalculateSummary(list.Subscriptions, list.Payments, rates, list.FiscalYearStart()), nil
+}
+
+// GetSettlements reports who owes whom per month for shared subscriptions,
+// based on the payments made between from and to
+func (s *SubscriptionService) GetSettlements(from, to time.Time) ([]calculator.MonthlySettlement, error) {
+	list, err := s.storage.Load()
+	if err != nil {
+		return nil, err
+	}
+
+	rates, err := s.rates.Load()
+	if err != nil {
+		return nil, err
+	}
+
+	return calculator.CalculateSettlements(list.Subscriptions, list.Payments, rates, from, to), nil
+}
+
+// GetForecast projects the charges due over the next months, month by month
+func (s *SubscriptionService) GetForecast(months int) (*calculator.Forecast, error) {
+	list, err := s.storage.Load()
+	if err != nil {
+		return nil, err
+	}
+
+	rates, err := s.rates.Load()
+	if err != nil {
+		return nil, err
+	}
+
+	return calculator.ForecastCashFlow(list.Subscriptions, rates, time.Now(), months), nil
+}
+
+// GetFiscalYearStart returns the month the fiscal year starts
+func (s *SubscriptionService) GetFiscalYearStart() (time.Month, error) {
+	list, err := s.storage.Load()
+	if err != nil {
+		return time.January, err
+	}
+	return list.FiscalYearStart(), nil
+}
+
+// SetFiscalYearStart sets the month the fiscal year starts; YTD and yearly reports follow it
+func (s *SubscriptionService) SetFiscalYearStart(month time.Month) error {
+	if month < time.January || month > time.December {
+		return ErrInvalidFiscalYearStart
+	}
+
+	list, err := s.storage.Load()
+	if err != nil {
+		return err
+	}
+
+	list.FiscalYearMonth = month
+	return s.storage.Save(list)
+}
+
+// ListBackups returns the snapshots of the data file, newest first
+func (s *SubscriptionService) ListBackups() ([]storage.Backup, error) {
+	backups, ok := s.storage.(storage.BackupStorage)
+	if !ok {
+		return nil, ErrBackupsUnsupported
+	}
+	return backups.ListBackups()
+}
+
+// RestoreBackup replaces all data with a snapshot; the current data is snapshotted first
+func (s *SubscriptionService) RestoreBackup(path string) error {
+	backups, ok := s.storage.(storage.BackupStorage)
+	if !ok {
+		return ErrBackupsUnsupported
+	}
+	return backups.RestoreBackup(path)
+}
+
+// WatchStorage calls onChange, from another goroutine, when another program
+// changes the stored data; storage that can't be watched is ignored
+func (s *SubscriptionService) WatchStorage(onChange func()) error {
+	watched, ok := s.storage.(storage.WatchedStorage)
+	if !ok {
+		return nil
+	}
+	return watched.Watch(onChange)
+}
+
+// AcceptStorageChanges takes the stored data, changes by other programs
+// included, as what the app shows, so saving is allowed again
+func (s *SubscriptionService) AcceptStorageChanges() error {
+	watched, ok := s.storage.(storage.WatchedStorage)
+	if !ok {
+		return nil
+	}
+	return watched.AcceptChanges()
+}
+
+// IsEncrypted reports whether the data is kept encrypted with a passphrase
+func (s *SubscriptionService) IsEncrypted() (bool, error) {
+	encrypted, ok := s.storage.(storage.EncryptedStorage)
+	if !ok {
+		return false, nil
+	}
+	return encrypted.IsEncrypted()
+}
+
+// IsLocked reports whether the data is encrypted and waiting for its passphrase
+func (s *SubscriptionService) IsLocked() bool {
+	encrypted, ok := s.storage.(storage.EncryptedStorage)
+	return ok && encrypted.IsLocked()
+}
+
+// Unlock opens encrypted data with its passphrase
+func (s *SubscriptionService) Unlock(passphrase string) error {
+	encrypted, ok := s.storage.(storage.EncryptedStorage)
+	if !ok {
+		return nil
+	}
+	return encrypted.Unlock(passphrase)
+}
+
+// SetPassphrase turns on encryption or changes the passphrase; an empty
+// passphrase turns encryption off. current is the passphrase in use, if any.
+func (s *SubscriptionService) SetPassphrase(current, passphrase string) error {
+	encrypted, ok := s.storage.(storage.EncryptedStorage)
+	if !ok {
+		return ErrEncryptionUnsupported
+	}
+	if passphrase != "" && len([]rune(passphrase)) < MinPassphraseLength {
+		return ErrPassphraseTooShort
+	}
+	return encrypted.SetPassphrase(current, passphrase)
+}
+
+// GetExchangeRates returns the user-maintained exchange-rate table
+func (s *SubscriptionService) GetExchangeRates() (*models.ExchangeRates, error) {
+	return s.rates.Load()
+}
+
+// SaveExchangeRates stores the exchange-rate table
+func (s *SubscriptionService) SaveExchangeRates(rates *models.ExchangeRates) error {
+	rates.UpdatedAt = time.Now()
+	return s.rates.Save(rates)
+}
+
+// SetBaseCurrency changes the base/display currency, re-expressing existing rates against it
+func (s *SubscriptionService) SetBaseCurrency(code string) error {
+	rates, err := s.rates.Load()
+	if err != nil {
+		return err
+	}
+
+	if err := rates.Rebase(code); err != nil {
+		return err
+	}
+
+	return s.rates.Save(rates)
 }
 
 // GetStorage returns the underlying storage for direct access
@@ -144,10 +546,26 @@ func (s *SubscriptionService) GetStorage() storage.Storage {
 	return s.storage
 }
 
+// repriceGeneratedPayments applies the price history to auto-generated payments from a date on
+func (s *SubscriptionService) repriceGeneratedPayments(payments []models.Payment, sub *models.Subscription, from time.Time) {
+	day := models.CalendarDay(from)
+	for i, payment := range payments {
+		if payment.SubscriptionID != sub.ID || !payment.IsGenerated() {
+			continue
+		}
+		if models.CalendarDay(payment.PaymentDate).Before(day) {
+			continue
+		}
+		payments[i].Amount = sub.PriceAt(payment.PaymentDate)
+	}
+}
+
 // filterSubscriptions applies filter criteria
-func (s *SubscriptionService) filterSubscriptions(subs []models.Subscription, filter *models.FilterCriteria) []models.Subscription {
+// The cost range is in the base currency of rates
+func (s *SubscriptionService) filterSubscriptions(subs []models.Subscription, filter *models.FilterCriteria, rates *models.ExchangeRates) []models.Subscription {
 	if filter == nil {
-		return subs
+		// No filter still keeps trashed subscriptions out of the list
+		filter = &models.FilterCriteria{ShowPaused: true, ShowCancelled: true}
 	}
 
 	var result []models.Subscription
@@ -163,6 +581,11 @@ func (s *SubscriptionService) filterSubscriptions(subs []models.Subscription, fi
 			continue
 		}
 
+		// Cancelled filter - if ShowCancelled is false, skip cancelled subscriptions
+		if !filter.ShowCancelled && sub.IsCancelled() {
+			continue
+		}
+
 		// Search term filter (name or notes)
 		if filter.SearchTerm != "" {
 			term := strings.ToLower(filter.SearchTerm)
@@ -177,16 +600,27 @@ func (s *SubscriptionService) filterSubscriptions(subs []models.Subscription, fi
 			continue
 		}
 
+		// Tag filter
+		if !sub.MatchesTags(filter.Tags, filter.TagMatch) {
+			continue
+		}
+
+		// Payment method filter
+		if filter.PaymentMethodID != nil && sub.PaymentMethodID != *filter.PaymentMethodID {
+			continue
+		}
+
 		// Billing cycle filter
 		if filter.BillingCycle != nil && sub.BillingCycle != *filter.BillingCycle {
 			continue
 		}
 
 		// Cost range filter
-		if filter.MinCost != nil && sub.Cost < *filter.MinCost {
+		cost, _ := rates.ToBase(sub.Cost)
+		if filter.MinCost != nil && cost.Float() < *filter.MinCost {
 			continue
 		}
-		if filter.MaxCost != nil && sub.Cost > *filter.MaxCost {
+		if filter.MaxCost != nil && cost.Float() > *filter.MaxCost {
 			continue
 		}
 
@@ -197,7 +631,13 @@ func (s *SubscriptionService) filterSubscriptions(subs []models.Subscription, fi
 }
 
 // sortSubscriptions sorts by field and order
-func (s *SubscriptionService) sortSubscriptions(subs []models.Subscription, sortBy models.SortField, order models.SortOrder) {
+// Costs in different currencies are compared in the base currency of rates
+func (s *SubscriptionService) sortSubscriptions(subs []models.Subscription, sortBy models.SortField, order models.SortOrder, rates *models.ExchangeRates) {
+	cost := func(sub models.Subscription) int64 {
+		converted, _ := rates.ToBase(sub.Cost)
+		return converted.Amount
+	}
+
 	sort.Slice(subs, func(i, j int) bool {
 		var less bool
 
@@ -205,7 +645,7 @@ func (s *SubscriptionService) sortSubscriptions(subs []models.Subscription, sort
 		case models.SortByName:
 			less = strings.ToLower(subs[i].Name) < strings.ToLower(subs[j].Name)
 		case models.SortByCost:
-			less = subs[i].Cost < subs[j].Cost
+			less = cost(subs[i]) < cost(subs[j])
 		case models.SortByNextPayment:
 			less = subs[i].NextPayment.Before(subs[j].NextPayment)
 		default:
@@ -0,0 +1,141 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"subman/internal/models"
+)
+
+func TestUpdateRefusesStaleSubscription(t *testing.T) {
+	subs, _ := newTestServices(t)
+	sub := createWeekly(t, subs)
+
+	// Two copies opened at the same time
+	first, _ := subs.Get(sub.ID)
+	second, _ := subs.Get(sub.ID)
+
+	first.Name = "First"
+	if err := subs.Update(first); err != nil {
+		t.Fatal(err)
+	}
+
+	second.Name = "Second"
+	if err := subs.Update(second); !errors.Is(err, ErrSubscriptionChanged) {
+		t.Fatalf("Update of a stale copy: error = %v, want ErrSubscriptionChanged", err)
+	}
+	if stored, _ := subs.Get(sub.ID); stored.Name != "First" {
+		t.Errorf("name = %q, want the first update kept", stored.Name)
+	}
+
+	// A copy taken after the change saves normally
+	third, _ := subs.Get(sub.ID)
+	third.Name = "Third"
+	if err := subs.Update(third); err != nil {
+		t.Fatalf("Update of a current copy: %v", err)
+	}
+}
+
+func TestPriceChangeRepricesFromCalendarDay(t *testing.T) {
+	subs, payments := newTestServices(t)
+	sub := createWeekly(t, subs)
+	list := generate(t, subs, payments, sub.ID)
+
+	// Late in the evening west of UTC on the day of the third payment, which
+	// is already the next day in UTC
+	day := list[2].PaymentDate
+	effective := time.Date(day.Year(), day.Month(), day.Day(), 23, 30, 0, 0, time.FixedZone("EDT", -4*60*60))
+
+	edited, _ := subs.Get(sub.ID)
+	edited.Cost = models.NewMoney(600, "USD")
+	if err := subs.UpdateEffective(edited, effective); err != nil {
+		t.Fatal(err)
+	}
+
+	list, err := payments.GetPaymentsForSubscription(sub.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, payment := range list {
+		want := models.NewMoney(500, "USD")
+		if !payment.PaymentDate.Before(day) {
+			want = models.NewMoney(600, "USD")
+		}
+		if payment.Amount != want {
+			t.Errorf("payment on %s = %v, want %v", payment.PaymentDate.Format("2006-01-02"), payment.Amount, want)
+		}
+	}
+}
+
+func TestListComparesCostsInBaseCurrency(t *testing.T) {
+	subs, _ := newTestServices(t)
+	rates := &models.ExchangeRates{Base: "USD", Rates: map[string]float64{"EUR": 1.1, "JPY": 0.0067}}
+	if err := subs.SaveExchangeRates(rates); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, sub := range []*models.Subscription{
+		{Name: "Dollars", Cost: models.NewMoney(2000, "USD"), BillingCycle: models.Monthly}, // 20.00 USD
+		{Name: "Yen", Cost: models.NewMoney(1500, "JPY"), BillingCycle: models.Monthly},     // 10.05 USD
+		{Name: "Euros", Cost: models.NewMoney(1500, "EUR"), BillingCycle: models.Monthly},   // 16.50 USD
+	} {
+		if err := subs.Create(sub); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	names := func(list []models.Subscription) []string {
+		var names []string
+		for _, sub := range list {
+			names = append(names, sub.Name)
+		}
+		return names
+	}
+
+	list, err := subs.List(nil, models.SortByCost, models.Ascending)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := names(list); len(got) != 3 || got[0] != "Yen" || got[1] != "Euros" || got[2] != "Dollars" {
+		t.Errorf("sorted by cost: %v, want [Yen Euros Dollars]", got)
+	}
+
+	min, max := 12.0, 18.0
+	filter := &models.FilterCriteria{MinCost: &min, MaxCost: &max, ShowPaused: true, ShowCancelled: true}
+	list, err = subs.List(filter, models.SortByName, models.Ascending)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := names(list); len(got) != 1 || got[0] != "Euros" {
+		t.Errorf("between 12 and 18 USD: %v, want [Euros]", got)
+	}
+}
+
+func TestCreateRefusesSharesOverCost(t *testing.T) {
+	subs, _ := newTestServices(t)
+
+	sub := &models.Subscription{
+		Name:         "Family plan",
+		Cost:         models.NewMoney(1000, "USD"),
+		BillingCycle: models.Monthly,
+		Sharing: &models.Sharing{Split: models.SplitFixed, Participants: []models.Participant{
+			{Name: "Bob", Fixed: models.NewMoney(1200, "USD")},
+		}},
+	}
+	if err := subs.Create(sub); !errors.Is(err, models.ErrSharesExceedCost) {
+		t.Fatalf("Create: error = %v, want ErrSharesExceedCost", err)
+	}
+
+	sub.Sharing.Participants[0].Fixed = models.NewMoney(400, "USD")
+	if err := subs.Create(sub); err != nil {
+		t.Fatal(err)
+	}
+
+	// Lowering the price below the shares is refused too
+	edited, _ := subs.Get(sub.ID)
+	edited.Cost = models.NewMoney(300, "USD")
+	if err := subs.Update(edited); !errors.Is(err, models.ErrSharesExceedCost) {
+		t.Errorf("Update: error = %v, want ErrSharesExceedCost", err)
+	}
+}
@@ -0,0 +1,92 @@
+package storage
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// tempFiles returns the temporary files writeFileAtomic left in dir
+func tempFiles(t *testing.T, dir string) []string {
+	t.Helper()
+
+	matches, err := filepath.Glob(filepath.Join(dir, ".*.tmp-*"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return matches
+}
+
+func TestWriteFileAtomic(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, defaultFileName)
+
+	for _, content := range []string{"first", "second"} {
+		if err := writeFileAtomic(path, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+		if data, _ := os.ReadFile(path); string(data) != content {
+			t.Errorf("file holds %q, want %q", data, content)
+		}
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if perm := info.Mode().Perm(); perm != 0600 {
+		t.Errorf("permissions = %v, want 0600", perm)
+	}
+	if left := tempFiles(t, dir); len(left) != 0 {
+		t.Errorf("left temporary files %v", left)
+	}
+}
+
+func TestWriteFileAtomicFailedRename(t *testing.T) {
+	dir := t.TempDir()
+
+	// A directory can't be replaced by a file, so the final step fails
+	path := filepath.Join(dir, defaultFileName)
+	if err := os.MkdirAll(filepath.Join(path, "keep"), 0700); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := writeFileAtomic(path, []byte("new"), 0600); err == nil {
+		t.Fatal("writeFileAtomic over a directory succeeded")
+	}
+	if info, err := os.Stat(path); err != nil || !info.IsDir() {
+		t.Error("failed write replaced the target")
+	}
+	if left := tempFiles(t, dir); len(left) != 0 {
+		t.Errorf("failed write left temporary files %v", left)
+	}
+}
+
+func TestLoadAfterInterruptedSave(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, defaultFileName)
+	store := NewJSONStorageWithPath(path)
+	saveSubscriptions(t, store, 2)
+
+	// A crash while saving leaves a partly written temporary file behind,
+	// and the data file as it was
+	stale := filepath.Join(dir, "."+defaultFileName+".tmp-123")
+	if err := os.WriteFile(stale, []byte(`{"subscriptions": [{"id": "a", "na`), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	store = NewJSONStorageWithPath(path)
+	list, err := store.Load()
+	if err != nil {
+		t.Fatalf("Load after an interrupted save: %v", err)
+	}
+	if len(list.Subscriptions) != 2 {
+		t.Fatalf("loaded %d subscriptions, want 2", len(list.Subscriptions))
+	}
+
+	// Saving again works and replaces the data file, not the leftover
+	saveSubscriptions(t, store, 3)
+	if list, _ := NewJSONStorageWithPath(path).Load(); len(list.Subscriptions) != 3 {
+		t.Errorf("loaded %d subscriptions after saving again, want 3", len(list.Subscriptions))
+	}
+}
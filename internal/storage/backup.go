@@ -0,0 +1,255 @@
+package storage
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"subman/internal/models"
+)
+
+const (
+	backupDirName    = "backups"
+	backupTimeFormat = "20060102-150405.000"
+)
+
+// ErrBackupNotFound is returned when restoring a snapshot that isn't a backup of this storage
+var ErrBackupNotFound = errors.New("backup not found")
+
+// BackupPolicy decides how often the data file is snapshotted and which snapshots are kept
+type BackupPolicy struct {
+	KeepLast      int           // Most recent snapshots always kept
+	KeepDailyDays int           // Beyond those, the newest snapshot of each of the last days is kept
+	MinInterval   time.Duration // No new snapshot within this long of the previous one
+}
+
+// DefaultBackupPolicy keeps the last 10 snapshots plus one a day for two weeks
+var DefaultBackupPolicy = BackupPolicy{
+	KeepLast:      10,
+	KeepDailyDays: 14,
+	MinInterval:   10 * time.Minute,
+}
+
+// Backup is a snapshot of the data file
+type Backup struct {
+	Path          string
+	CreatedAt     time.Time
+	Subscriptions int // Subscriptions in the snapshot, not counting deleted ones
+	Payments      int
+	Err           error // Set if the snapshot can't be read
+}
+
+// BackupStorage is a Storage that keeps snapshots of its data to restore from
+type BackupStorage interface {
+	Storage
+
+	// ListBackups returns the snapshots, newest first
+	ListBackups() ([]Backup, error)
+
+	// RestoreBackup replaces the current data with a snapshot
+	RestoreBackup(path string) error
+}
+
+// backupDir returns the folder snapshots of dataPath are kept in
+func backupDir(dataPath string) string {
+	return filepath.Join(filepath.Dir(dataPath), backupDirName)
+}
+
+// backupName returns the snapshot file name for dataPath taken at t,
+// e.g. "subscriptions-20261017-153045.000.json"
+func backupName(dataPath string, t time.Time) string {
+	base := filepath.Base(dataPath)
+	ext := filepath.Ext(base)
+	return strings.TrimSuffix(base, ext) + "-" + t.Format(backupTimeFormat) + ext
+}
+
+// backupTime parses the time a snapshot was taken from its file name
+func backupTime(dataPath, name string) (time.Time, bool) {
+	base := filepath.Base(dataPath)
+	ext := filepath.Ext(base)
+	prefix := strings.TrimSuffix(base, ext) + "-"
+	if !strings.HasPrefix(name, prefix) || !strings.HasSuffix(name, ext) {
+		return time.Time{}, false
+	}
+	stamp := strings.TrimSuffix(strings.TrimPrefix(name, prefix), ext)
+	t, err := time.ParseInLocation(backupTimeFormat, stamp, time.Local)
+	return t, err == nil
+}
+
+// snapshot copies the current data file into the backup folder and prunes old
+// snapshots; force ignores the policy's minimum interval
+func snapshot(dataPath string, policy BackupPolicy, now time.Time, force bool) error {
+	data, err := os.ReadFile(dataPath)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	dir := backupDir(dataPath)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return err
+	}
+
+	backups, err := listBackupFiles(dataPath)
+	if err != nil {
+		return err
+	}
+	if !force && len(backups) > 0 && now.Sub(backups[0].CreatedAt) < policy.MinInterval {
+		return nil
+	}
+
+	if err := writeFileAtomic(filepath.Join(dir, backupName(dataPath, now)), data, 0600); err != nil {
+		return fmt.Errorf("failed to back up %s: %w", filepath.Base(dataPath), err)
+	}
+
+	return pruneBackups(dataPath, policy, now)
+}
+
+// pruneBackups deletes the snapshots the policy no longer keeps
+func pruneBackups(dataPath string, policy BackupPolicy, now time.Time) error {
+	backups, err := listBackupFiles(dataPath)
+	if err != nil {
+		return err
+	}
+
+	cutoff := now.AddDate(0, 0, -policy.KeepDailyDays)
+	days := make(map[string]bool)
+	for i, backup := range backups {
+		day := backup.CreatedAt.Format("2006-01-02")
+		keep := i < policy.KeepLast || (backup.CreatedAt.After(cutoff) && !days[day])
+		days[day] = true
+		if keep {
+			continue
+		}
+		if err := os.Remove(backup.Path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// listBackupFiles returns the snapshots of dataPath, newest first, without reading them
+func listBackupFiles(dataPath string) ([]Backup, error) {
+	entries, err := os.ReadDir(backupDir(dataPath))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var backups []Backup
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		if t, ok := backupTime(dataPath, entry.Name()); ok {
+			backups = append(backups, Backup{
+				Path:      filepath.Join(backupDir(dataPath), entry.Name()),
+				CreatedAt: t,
+			})
+		}
+	}
+
+	sort.Slice(backups, func(i, j int) bool {
+		return backups[i].CreatedAt.After(backups[j].CreatedAt)
+	})
+	return backups, nil
+}
+
+// readBackups lists the snapshots of dataPath with what each contains; open
+// decrypts encrypted snapshots
+func readBackups(dataPath string, open func([]byte) ([]byte, error)) ([]Backup, error) {
+	backups, err := listBackupFiles(dataPath)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range backups {
+		data, err := os.ReadFile(backups[i].Path)
+		if err == nil {
+			data, err = open(data)
+		}
+		if err != nil {
+			backups[i].Err = err
+			continue
+		}
+
+		doc, err := decodeDocument(data)
+		if err == nil {
+			err = checkVersion(doc.version())
+		}
+		if err != nil {
+			backups[i].Err = err
+			continue
+		}
+
+		var list models.SubscriptionList
+		if err := json.Unmarshal(data, &list); err != nil {
+			backups[i].Err = err
+			continue
+		}
+		for _, sub := range list.Subscriptions {
+			if !sub.Deleted {
+				backups[i].Subscriptions++
+			}
+		}
+		backups[i].Payments = len(list.Payments)
+	}
+
+	return backups, nil
+}
+
+// rekeyBackups re-encrypts the snapshots of dataPath after the passphrase
+// changes: each is decrypted with open and written back through seal
+// Snapshots open can't read are left as they are.
+func rekeyBackups(dataPath string, open, seal func([]byte) ([]byte, error)) error {
+	backups, err := listBackupFiles(dataPath)
+	if err != nil {
+		return err
+	}
+
+	for _, backup := range backups {
+		if err := rekeyFile(backup.Path, open, seal); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// rekeyFile decrypts the file at path with open and writes it back through seal
+// A missing file or one open can't read is left as it is.
+func rekeyFile(path string, open, seal func([]byte) ([]byte, error)) error {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	plain, err := open(data)
+	if err != nil {
+		return nil
+	}
+	sealed, err := seal(plain)
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(path, sealed, 0600)
+}
+
+// isBackupOf reports whether path is one of the snapshots of dataPath
+func isBackupOf(dataPath, path string) bool {
+	if filepath.Dir(filepath.Clean(path)) != backupDir(dataPath) {
+		return false
+	}
+	_, ok := backupTime(dataPath, filepath.Base(path))
+	return ok
+}
@@ -0,0 +1,129 @@
+package storage
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestBackupRotation(t *testing.T) {
+	path := filepath.Join(t.TempDir(), defaultFileName)
+	if err := os.WriteFile(path, []byte(`{}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	policy := BackupPolicy{KeepLast: 3, KeepDailyDays: 5, MinInterval: 10 * time.Minute}
+	today := time.Date(2026, 10, 17, 0, 0, 0, 0, time.Local)
+	at := func(days, hour, minute int) time.Time {
+		return today.AddDate(0, 0, days).Add(time.Duration(hour)*time.Hour + time.Duration(minute)*time.Minute)
+	}
+
+	for _, now := range []time.Time{
+		at(-10, 12, 0), // Older than the daily snapshots
+		at(-4, 9, 0),   // Not the last of its day
+		at(-4, 18, 0),
+		at(-2, 10, 0),
+		at(0, 8, 0), // Not the last of its day, nor among the last three
+		at(0, 9, 0),
+		at(0, 9, 5), // Within the minimum interval, so never taken
+		at(0, 10, 0),
+		at(0, 11, 0),
+	} {
+		if err := snapshot(path, policy, now, false); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	backups, err := listBackupFiles(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []time.Time{at(0, 11, 0), at(0, 10, 0), at(0, 9, 0), at(-2, 10, 0), at(-4, 18, 0)}
+	if len(backups) != len(want) {
+		t.Fatalf("kept %d backups, want %d: %v", len(backups), len(want), backups)
+	}
+	for i, backup := range backups {
+		if !backup.CreatedAt.Equal(want[i]) {
+			t.Errorf("backup %d taken %v, want %v", i, backup.CreatedAt, want[i])
+		}
+	}
+
+	// A forced snapshot ignores the interval
+	if err := snapshot(path, policy, at(0, 11, 1), true); err != nil {
+		t.Fatal(err)
+	}
+	if backups, _ := listBackupFiles(path); !backups[0].CreatedAt.Equal(at(0, 11, 1)) {
+		t.Error("forced snapshot was not taken")
+	}
+}
+
+func TestRestoreBackup(t *testing.T) {
+	path := filepath.Join(t.TempDir(), defaultFileName)
+	store := NewJSONStorageWithPath(path)
+	saveSubscriptions(t, store, 1)
+	saveSubscriptions(t, store, 2) // Backs up the one-subscription version
+
+	backups, err := store.ListBackups()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(backups) != 1 || backups[0].Subscriptions != 1 {
+		t.Fatalf("backups = %+v, want one with 1 subscription", backups)
+	}
+
+	if err := store.RestoreBackup(backups[0].Path); err != nil {
+		t.Fatalf("RestoreBackup: %v", err)
+	}
+	if list, _ := store.Load(); len(list.Subscriptions) != 1 {
+		t.Fatalf("restored %d subscriptions, want 1", len(list.Subscriptions))
+	}
+
+	// The data replaced by the restore was backed up, well within the minimum interval
+	backups, err = store.ListBackups()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(backups) != 2 || backups[0].Subscriptions != 2 {
+		t.Fatalf("backups = %+v, want the two-subscription version newest", backups)
+	}
+
+	// So the restore can be undone
+	if err := store.RestoreBackup(backups[0].Path); err != nil {
+		t.Fatalf("RestoreBackup: %v", err)
+	}
+	if list, _ := store.Load(); len(list.Subscriptions) != 2 {
+		t.Fatalf("undone restore left %d subscriptions, want 2", len(list.Subscriptions))
+	}
+}
+
+func TestRestoreBackupRefusesOtherFiles(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, defaultFileName)
+	store := NewJSONStorageWithPath(path)
+	saveSubscriptions(t, store, 1)
+
+	// Only snapshots of this data file in its backup folder can be restored
+	other := filepath.Join(backupDir(path), backupName(filepath.Join(dir, "other.json"), time.Now()))
+	for _, file := range []string{path, other} {
+		if err := store.RestoreBackup(file); !errors.Is(err, ErrBackupNotFound) {
+			t.Errorf("RestoreBackup(%s): error = %v, want ErrBackupNotFound", filepath.Base(file), err)
+		}
+	}
+
+	// A snapshot from a newer version is refused without touching the data
+	if err := os.MkdirAll(backupDir(path), 0700); err != nil {
+		t.Fatal(err)
+	}
+	newer := filepath.Join(backupDir(path), backupName(path, time.Now()))
+	if err := os.WriteFile(newer, []byte(`{"version": "9.0", "subscriptions": []}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.RestoreBackup(newer); !errors.Is(err, ErrNewerVersion) {
+		t.Errorf("RestoreBackup of a newer snapshot: error = %v, want ErrNewerVersion", err)
+	}
+	if list, _ := store.Load(); len(list.Subscriptions) != 1 {
+		t.Errorf("refused restore changed the data")
+	}
+}
@@ -0,0 +1,194 @@
+package storage
+
+import (
+	"errors"
+	"os"
+	"time"
+
+	"subman/pkg/vault"
+)
+
+// ErrLocked is returned when reading encrypted data before it is unlocked
+var ErrLocked = errors.New("data file is encrypted; unlock it with your passphrase first")
+
+// EncryptedStorage is a Storage that can keep its data encrypted with a passphrase
+type EncryptedStorage interface {
+	Storage
+
+	// IsEncrypted reports whether the data is kept encrypted
+	IsEncrypted() (bool, error)
+
+	// IsLocked reports whether the data is encrypted and not unlocked yet
+	IsLocked() bool
+
+	// Unlock derives the key from passphrase so encrypted data can be read and saved
+	Unlock(passphrase string) error
+
+	// SetPassphrase encrypts the data with passphrase, or decrypts it if passphrase
+	// is empty; current must be the passphrase the data is encrypted with now
+	SetPassphrase(current, passphrase string) error
+}
+
+// IsEncrypted reports whether the data file is encrypted, or will be on the next save
+func (s *JSONStorage) IsEncrypted() (bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if s.key != nil {
+		return true, nil
+	}
+	return s.fileSealed()
+}
+
+// IsLocked reports whether the data file is encrypted and no passphrase has been given
+func (s *JSONStorage) IsLocked() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	sealed, _ := s.fileSealed()
+	return sealed && s.key == nil
+}
+
+// Unlock checks passphrase against the data file and keeps the derived key
+// An unencrypted data file needs no unlocking.
+func (s *JSONStorage) Unlock(passphrase string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	data, err := os.ReadFile(s.filePath)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if !vault.IsSealed(data) {
+		return nil
+	}
+
+	_, key, err := vault.Open(data, passphrase)
+	if err != nil {
+		return err
+	}
+	s.key = key
+	return nil
+}
+
+// SetPassphrase encrypts the data file and its backups with passphrase, or
+// decrypts them when passphrase is empty
+// The file as it was is snapshotted first. Backups sealed with a passphrase
+// from before an earlier change can't be read and are left as they are. A copy
+// left behind by a migration to SQLite is encrypted too, so no plaintext copy
+// of the data stays next to the encrypted file.
+func (s *JSONStorage) SetPassphrase(current, passphrase string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	data, err := os.ReadFile(s.filePath)
+	exists := err == nil
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	// Changing or removing a passphrase needs the current one, even when unlocked
+	oldKey := s.key
+	if exists && vault.IsSealed(data) {
+		if data, oldKey, err = vault.Open(data, current); err != nil {
+			return err
+		}
+	}
+	if oldKey == nil && passphrase == "" {
+		return nil // Not encrypted to begin with
+	}
+
+	var newKey *vault.Key
+	if passphrase != "" {
+		if newKey, err = vault.NewKey(passphrase); err != nil {
+			return err
+		}
+	}
+
+	if exists {
+		if err := snapshot(s.filePath, s.backups, time.Now(), true); err != nil {
+			return err
+		}
+	}
+
+	s.key = newKey
+	if exists {
+		if err := s.write(data); err != nil {
+			return err
+		}
+	}
+
+	open := func(data []byte) ([]byte, error) { return openData(oldKey, data) }
+	seal := func(data []byte) ([]byte, error) { return sealData(newKey, data) }
+	if err := rekeyFile(s.filePath+migratedSuffix, open, seal); err != nil {
+		return err
+	}
+	return rekeyBackups(s.filePath, open, seal)
+}
+
+// fileSealed reports whether the data file on disk is encrypted
+func (s *JSONStorage) fileSealed() (bool, error) {
+	data, err := os.ReadFile(s.filePath)
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return vault.IsSealed(data), nil
+}
+
+// read returns the contents of path, decrypted if encrypted
+func (s *JSONStorage) read(path string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return s.open(data)
+}
+
+// write replaces the data file with data, encrypted if a passphrase is set
+func (s *JSONStorage) write(data []byte) error {
+	sealed, err := s.seal(data)
+	if err != nil {
+		return err
+	}
+	if err := writeFileAtomic(s.filePath, sealed, 0600); err != nil {
+		return err
+	}
+
+	// Our own write isn't a change by another program
+	return s.track()
+}
+
+// open decrypts data if it is encrypted
+func (s *JSONStorage) open(data []byte) ([]byte, error) {
+	return openData(s.key, data)
+}
+
+// seal encrypts data if a passphrase is set
+func (s *JSONStorage) seal(data []byte) ([]byte, error) {
+	return sealData(s.key, data)
+}
+
+// openData decrypts data with key if it is encrypted
+func openData(key *vault.Key, data []byte) ([]byte, error) {
+	if !vault.IsSealed(data) {
+		return data, nil
+	}
+	if key == nil {
+		return nil, ErrLocked
+	}
+	return key.Open(data)
+}
+
+// sealData encrypts data with key, or returns it as is without one
+func sealData(key *vault.Key, data []byte) ([]byte, error) {
+	if key == nil {
+		return data, nil
+	}
+	return key.Seal(data)
+}
@@ -0,0 +1,161 @@
+package storage
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"subman/internal/models"
+	"subman/pkg/vault"
+)
+
+// Cheap settings keep the tests fast; the format is the same
+func init() {
+	vault.DefaultParams = vault.Params{Time: 1, Memory: 8 * 1024, Threads: 1}
+}
+
+// saveSubscriptions saves a list of n subscriptions
+func saveSubscriptions(t *testing.T, store *JSONStorage, n int) {
+	t.Helper()
+
+	list := &models.SubscriptionList{}
+	for i := 0; i < n; i++ {
+		list.Subscriptions = append(list.Subscriptions, models.Subscription{ID: string(rune('a' + i)), Name: "Sub"})
+	}
+	if err := store.Save(list); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(2 * time.Millisecond) // Backups are named to the millisecond
+}
+
+// checkSealed checks that every file is encrypted, or that none is when sealed is false
+func checkSealed(t *testing.T, sealed bool, paths ...string) {
+	t.Helper()
+
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if vault.IsSealed(data) != sealed {
+			t.Errorf("%s: sealed = %v, want %v", filepath.Base(path), !sealed, sealed)
+		}
+	}
+}
+
+func TestSetPassphraseRekeysBackups(t *testing.T) {
+	path := filepath.Join(t.TempDir(), defaultFileName)
+	store := NewJSONStorageWithPath(path)
+	store.SetBackupPolicy(BackupPolicy{KeepLast: 10})
+	for n := 1; n <= 3; n++ {
+		saveSubscriptions(t, store, n)
+	}
+
+	backupPaths := func() []string {
+		backups, err := store.ListBackups()
+		if err != nil {
+			t.Fatal(err)
+		}
+		var paths []string
+		for _, backup := range backups {
+			if backup.Err != nil {
+				t.Errorf("%s: %v", filepath.Base(backup.Path), backup.Err)
+			}
+			paths = append(paths, backup.Path)
+		}
+		return paths
+	}
+
+	// Encrypting seals the data file and every backup
+	if err := store.SetPassphrase("", "first passphrase"); err != nil {
+		t.Fatal(err)
+	}
+	checkSealed(t, true, append(backupPaths(), path)...)
+
+	// Changing the passphrase needs the current one
+	if err := store.SetPassphrase("wrong passphrase", "second passphrase"); !errors.Is(err, vault.ErrWrongPassphrase) {
+		t.Fatalf("SetPassphrase with the wrong passphrase: error = %v, want ErrWrongPassphrase", err)
+	}
+	if err := store.SetPassphrase("first passphrase", "second passphrase"); err != nil {
+		t.Fatal(err)
+	}
+
+	// A fresh storage opens the file and every backup with the new passphrase only
+	reopened := NewJSONStorageWithPath(path)
+	if !reopened.IsLocked() {
+		t.Fatal("encrypted storage is not locked")
+	}
+	if _, err := reopened.Load(); !errors.Is(err, ErrLocked) {
+		t.Errorf("Load while locked: error = %v, want ErrLocked", err)
+	}
+	if err := reopened.Unlock("first passphrase"); !errors.Is(err, vault.ErrWrongPassphrase) {
+		t.Errorf("Unlock with the old passphrase: error = %v, want ErrWrongPassphrase", err)
+	}
+	if err := reopened.Unlock("second passphrase"); err != nil {
+		t.Fatal(err)
+	}
+	list, err := reopened.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list.Subscriptions) != 3 {
+		t.Errorf("got %d subscriptions, want 3", len(list.Subscriptions))
+	}
+	store = reopened
+	backups := backupPaths()
+	if len(backups) != 4 { // The file before the last two saves and before each passphrase change
+		t.Errorf("got %d backups, want 4", len(backups))
+	}
+
+	// Turning encryption off decrypts them all again
+	if err := store.SetPassphrase("second passphrase", ""); err != nil {
+		t.Fatal(err)
+	}
+	checkSealed(t, false, append(backupPaths(), path)...)
+}
+
+func TestSetPassphraseSealsMigratedCopy(t *testing.T) {
+	path := filepath.Join(t.TempDir(), defaultFileName)
+	if err := os.WriteFile(path+migratedSuffix, []byte(`{"version": "1.3", "subscriptions": []}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	store := NewJSONStorageWithPath(path)
+	saveSubscriptions(t, store, 1)
+	if err := store.SetPassphrase("", "first passphrase"); err != nil {
+		t.Fatal(err)
+	}
+	checkSealed(t, true, path, path+migratedSuffix)
+}
+
+func TestMigrateEncryptedToSQLite(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, defaultFileName)
+	store := NewJSONStorageWithPath(path)
+	saveSubscriptions(t, store, 2)
+	if err := store.SetPassphrase("", "first passphrase"); err != nil {
+		t.Fatal(err)
+	}
+
+	db, err := NewSQLiteStorageWithPath(filepath.Join(dir, defaultDBFileName))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	migrated, err := MigrateJSONToSQLite(path, db)
+	if !errors.Is(err, ErrEncryptedMigration) || migrated {
+		t.Fatalf("MigrateJSONToSQLite = %v, %v, want ErrEncryptedMigration", migrated, err)
+	}
+
+	// Nothing was copied out of the encrypted file, and it stays where it was
+	if empty, err := db.IsEmpty(); err != nil || !empty {
+		t.Errorf("database empty = %v, %v", empty, err)
+	}
+	checkSealed(t, true, path)
+	if _, err := os.Stat(path + migratedSuffix); !os.IsNotExist(err) {
+		t.Errorf("encrypted file was renamed: %v", err)
+	}
+}
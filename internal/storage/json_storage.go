@@ -2,20 +2,30 @@ package storage
 
 import (
 	"encoding/json"
+	"fmt"
 	"os"
 	"path/filepath"
 	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
 
 	"subman/internal/models"
+	"subman/pkg/vault"
 )
 
 const (
 	defaultFileName = "subscriptions.json"
-	dataVersion     = "1.0"
+	dataVersion     = "1.3"
 )
 
 type JSONStorage struct {
 	filePath string
+	backups  BackupPolicy
+	key      *vault.Key // Set while the data file is encrypted and unlocked
+	known    fileState  // The data file as the app last saw it, to notice other writers
+	tracking bool       // Set once known has been recorded
+	watcher  *fsnotify.Watcher
 	mu       sync.RWMutex
 }
 
@@ -38,6 +48,7 @@ func NewJSONStorage() (*JSONStorage, error) {
 
 	return &JSONStorage{
 		filePath: filePath,
+		backups:  DefaultBackupPolicy,
 	}, nil
 }
 
@@ -45,26 +56,46 @@ func NewJSONStorage() (*JSONStorage, error) {
 func NewJSONStorageWithPath(path string) *JSONStorage {
 	return &JSONStorage{
 		filePath: path,
+		backups:  DefaultBackupPolicy,
 	}
 }
 
+// SetBackupPolicy changes how often the data file is snapshotted and how many snapshots are kept
+func (s *JSONStorage) SetBackupPolicy(policy BackupPolicy) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.backups = policy
+}
+
+// Load reads the data file, first upgrading files saved by older versions
+// A file that needs migrating is snapshotted, then rewritten in the current format.
 func (s *JSONStorage) Load() (*models.SubscriptionList, error) {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	raw, err := s.readData()
+	if err != nil {
+		return nil, err
+	}
 
 	// If file doesn't exist, return empty list
-	if _, err := os.Stat(s.filePath); os.IsNotExist(err) {
+	if raw == nil {
 		return &models.SubscriptionList{
 			Subscriptions: []models.Subscription{},
 			Version:       dataVersion,
 		}, nil
 	}
 
-	data, err := os.ReadFile(s.filePath)
+	data, err := s.open(raw)
 	if err != nil {
 		return nil, err
 	}
 
+	if data, err = s.migrate(data); err != nil {
+		return nil, err
+	}
+
 	var list models.SubscriptionList
 	if err := json.Unmarshal(data, &list); err != nil {
 		return nil, err
@@ -73,6 +104,35 @@ func (s *JSONStorage) Load() (*models.SubscriptionList, error) {
 	return &list, nil
 }
 
+// migrate upgrades the data file to the current version if it is older,
+// returning the data to read
+func (s *JSONStorage) migrate(data []byte) ([]byte, error) {
+	doc, err := decodeDocument(data)
+	if err != nil {
+		return nil, err
+	}
+
+	migrated, err := migrateDocument(doc)
+	if err != nil || !migrated {
+		return data, err
+	}
+
+	upgraded, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	// Keep the file as it was before migrating, whatever the backup interval
+	if err := snapshot(s.filePath, s.backups, time.Now(), true); err != nil {
+		return nil, err
+	}
+	if err := s.write(upgraded); err != nil {
+		return nil, err
+	}
+
+	return upgraded, nil
+}
+
 func (s *JSONStorage) Save(list *models.SubscriptionList) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
@@ -84,7 +144,59 @@ func (s *JSONStorage) Save(list *models.SubscriptionList) error {
 		return err
 	}
 
-	return os.WriteFile(s.filePath, data, 0600)
+	// Never overwrite changes another program made since the data was loaded
+	if err := s.checkUnchanged(); err != nil {
+		return err
+	}
+
+	// Snapshot the previous version before replacing it
+	if err := snapshot(s.filePath, s.backups, time.Now(), false); err != nil {
+		return err
+	}
+
+	return s.write(data)
+}
+
+// ListBackups returns the snapshots of the data file, newest first
+func (s *JSONStorage) ListBackups() ([]Backup, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return readBackups(s.filePath, s.open)
+}
+
+// RestoreBackup replaces the data file with a snapshot
+// The current data is snapshotted first, so a restore can be undone; snapshots
+// from older versions are migrated on the next Load.
+func (s *JSONStorage) RestoreBackup(path string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !isBackupOf(s.filePath, path) {
+		return ErrBackupNotFound
+	}
+
+	data, err := s.read(path)
+	if err != nil {
+		return err
+	}
+	doc, err := decodeDocument(data)
+	if err != nil {
+		return fmt.Errorf("backup is not readable: %w", err)
+	}
+	if err := checkVersion(doc.version()); err != nil {
+		return err
+	}
+	var list models.SubscriptionList
+	if err := json.Unmarshal(data, &list); err != nil {
+		return fmt.Errorf("backup is not readable: %w", err)
+	}
+
+	if err := snapshot(s.filePath, s.backups, time.Now(), true); err != nil {
+		return err
+	}
+
+	return s.write(data)
 }
 
 func (s *JSONStorage) GetPath() string {
@@ -0,0 +1,283 @@
+package storage
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"subman/internal/models"
+)
+
+var (
+	// ErrNewerVersion is returned for data files written by a newer version of subman
+	ErrNewerVersion = errors.New("data file was written by a newer version of subman")
+
+	// ErrUnknownVersion is returned for data files whose version has no migration path
+	ErrUnknownVersion = errors.New("unknown data file version")
+)
+
+// legacyVersion is assumed for files saved before the version was stamped
+const legacyVersion = "1.0"
+
+// document is a data file decoded without the models, so older layouts can be
+// rewritten before they are read into the current structs
+type document map[string]any
+
+// migration upgrades a document from one version to the next
+type migration struct {
+	from    string
+	to      string
+	migrate func(doc document) error
+}
+
+// migrations is the upgrade path, oldest first; each step's to is the next
+// step's from, and the last step's to is dataVersion
+var migrations = []migration{
+	{from: "1.0", to: "1.1", migrate: migrateMoneyObjects},
+	{from: "1.1", to: "1.2", migrate: migratePaymentKinds},
+	{from: "1.2", to: "1.3", migrate: migratePauseHistory},
+}
+
+// decodeDocument parses a data file, keeping numbers exact
+func decodeDocument(data []byte) (document, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var doc document
+	if err := decoder.Decode(&doc); err != nil {
+		return nil, err
+	}
+	if doc == nil {
+		return nil, errors.New("data file is empty")
+	}
+	return doc, nil
+}
+
+// DecodeList reads the contents of a data file of any version, such as the one
+// in an exported bundle, upgrading it to the current format in memory
+// Data from a newer version of subman is refused with ErrNewerVersion.
+func DecodeList(data []byte) (*models.SubscriptionList, error) {
+	doc, err := decodeDocument(data)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := migrateDocument(doc); err != nil {
+		return nil, err
+	}
+
+	upgraded, err := json.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+	var list models.SubscriptionList
+	if err := json.Unmarshal(upgraded, &list); err != nil {
+		return nil, err
+	}
+	return &list, nil
+}
+
+// version returns the version the document was saved with
+func (doc document) version() string {
+	if v, ok := doc["version"].(string); ok && v != "" {
+		return v
+	}
+	return legacyVersion
+}
+
+// checkVersion returns ErrNewerVersion if a file of this version can't be read safely
+func checkVersion(version string) error {
+	if compareVersions(version, dataVersion) > 0 {
+		return fmt.Errorf("%w: the file is version %s, this version of subman reads up to %s",
+			ErrNewerVersion, version, dataVersion)
+	}
+	return nil
+}
+
+// migrateDocument upgrades doc to dataVersion one step at a time
+// It returns whether anything was migrated.
+func migrateDocument(doc document) (bool, error) {
+	version := doc.version()
+	if err := checkVersion(version); err != nil {
+		return false, err
+	}
+
+	migrated := false
+	for version != dataVersion {
+		step, ok := findMigration(version)
+		if !ok {
+			return migrated, fmt.Errorf("%w: %s", ErrUnknownVersion, version)
+		}
+		if err := step.migrate(doc); err != nil {
+			return migrated, fmt.Errorf("failed to migrate data from version %s to %s: %w", step.from, step.to, err)
+		}
+		version = step.to
+		doc["version"] = version
+		migrated = true
+	}
+	return migrated, nil
+}
+
+// findMigration returns the step that upgrades from version
+func findMigration(version string) (migration, bool) {
+	for _, m := range migrations {
+		if m.from == version {
+			return m, true
+		}
+	}
+	return migration{}, false
+}
+
+// compareVersions compares dotted versions such as "1.2" and "1.10" part by part
+// Parts that aren't numbers compare as zero.
+func compareVersions(a, b string) int {
+	as := strings.Split(a, ".")
+	bs := strings.Split(b, ".")
+	for i := 0; i < len(as) || i < len(bs); i++ {
+		var x, y int
+		if i < len(as) {
+			x, _ = strconv.Atoi(as[i])
+		}
+		if i < len(bs) {
+			y, _ = strconv.Atoi(bs[i])
+		}
+		switch {
+		case x < y:
+			return -1
+		case x > y:
+			return 1
+		}
+	}
+	return 0
+}
+
+// migrateMoneyObjects (1.0 -> 1.1) turns bare decimal costs and amounts with an
+// optional sibling "currency" field into {amount, currency} objects in minor units
+func migrateMoneyObjects(doc document) error {
+	for _, sub := range doc.records("subscriptions") {
+		currency, _ := sub["currency"].(string)
+		if err := convertMoney(sub, "cost", currency); err != nil {
+			return err
+		}
+
+		// The post-trial price was always in the subscription's currency
+		if cost, ok := sub["cost"].(map[string]any); ok {
+			if c, ok := cost["currency"].(string); ok && c != "" {
+				currency = c
+			}
+		}
+		if err := convertMoney(sub, "post_trial_cost", currency); err != nil {
+			return err
+		}
+		delete(sub, "currency")
+	}
+
+	for _, payment := range doc.records("payments") {
+		currency, _ := payment["currency"].(string)
+		if err := convertMoney(payment, "amount", currency); err != nil {
+			return err
+		}
+		delete(payment, "currency")
+	}
+	return nil
+}
+
+// migratePaymentKinds (1.1 -> 1.2) records the type and source of payments saved
+// before they had them: all were charges, and the auto-generated ones are
+// recognized by their note
+func migratePaymentKinds(doc document) error {
+	for _, payment := range doc.records("payments") {
+		if t, _ := payment["type"].(string); t == "" {
+			payment["type"] = string(models.PaymentCharged)
+		}
+		if s, _ := payment["source"].(string); s == "" {
+			source := models.SourceManual
+			if notes, _ := payment["notes"].(string); notes == models.AutoGeneratedNote {
+				source = models.SourceGenerated
+			}
+			payment["source"] = string(source)
+		}
+	}
+	return nil
+}
+
+// migratePauseHistory (1.2 -> 1.3) opens a pause for subscriptions paused
+// before pause history was kept, so the paused span isn't billed once they
+// resume. The pause starts at the last edit, or the day after the last payment
+// if that is later.
+func migratePauseHistory(doc document) error {
+	lastPayment := make(map[string]time.Time)
+	for _, payment := range doc.records("payments") {
+		id, _ := payment["subscription_id"].(string)
+		if date, ok := recordTime(payment, "payment_date"); ok && date.After(lastPayment[id]) {
+			lastPayment[id] = date
+		}
+	}
+
+	for _, sub := range doc.records("subscriptions") {
+		paused, _ := sub["paused"].(bool)
+		pauses, _ := sub["pauses"].([]any)
+		if !paused || len(pauses) > 0 {
+			continue
+		}
+
+		start, _ := recordTime(sub, "updated_at")
+		id, _ := sub["id"].(string)
+		if last, ok := lastPayment[id]; ok && !last.Before(start) {
+			start = last.AddDate(0, 0, 1)
+		}
+		if start.IsZero() {
+			start, _ = recordTime(sub, "start_date")
+		}
+
+		sub["pauses"] = []any{map[string]any{
+			"start":  start.Format(time.RFC3339Nano),
+			"resume": time.Time{}.Format(time.RFC3339Nano),
+		}}
+	}
+	return nil
+}
+
+// recordTime parses the timestamp stored under key; zero times count as missing
+func recordTime(record map[string]any, key string) (time.Time, bool) {
+	text, _ := record[key].(string)
+	t, err := time.Parse(time.RFC3339Nano, text)
+	if err != nil || t.IsZero() {
+		return time.Time{}, false
+	}
+	return t, true
+}
+
+// records returns the objects in the list stored under key
+func (doc document) records(key string) []map[string]any {
+	items, _ := doc[key].([]any)
+	records := make([]map[string]any, 0, len(items))
+	for _, item := range items {
+		if record, ok := item.(map[string]any); ok {
+			records = append(records, record)
+		}
+	}
+	return records
+}
+
+// convertMoney replaces a bare decimal under key with a Money object; values
+// that are already objects are left alone
+func convertMoney(record map[string]any, key, currency string) error {
+	number, ok := record[key].(json.Number)
+	if !ok {
+		return nil
+	}
+
+	m, err := models.ParseMoney(number.String(), currency)
+	if err != nil {
+		return fmt.Errorf("%s: %w", key, err)
+	}
+	record[key] = map[string]any{
+		"amount":   m.Amount,
+		"currency": m.Currency,
+	}
+	return nil
+}
@@ -0,0 +1,299 @@
+package storage
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"subman/internal/models"
+)
+
+// copyFixture copies testdata/name into a fresh directory and returns its path
+func copyFixture(t *testing.T, name string) string {
+	t.Helper()
+
+	data, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), defaultFileName)
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestMigrationsReachDataVersion(t *testing.T) {
+	version := legacyVersion
+	for _, m := range migrations {
+		if m.from != version {
+			t.Fatalf("migration from %s follows one to %s", m.from, version)
+		}
+		if compareVersions(m.to, m.from) <= 0 {
+			t.Fatalf("migration from %s goes back to %s", m.from, m.to)
+		}
+		version = m.to
+	}
+	if version != dataVersion {
+		t.Fatalf("migrations end at %s, data version is %s", version, dataVersion)
+	}
+}
+
+func TestLoadMigratesEachVersion(t *testing.T) {
+	// v1.0.json is as saved by the first release, before currencies and trials;
+	// the later fixtures have Spotify in a free trial billed in EUR
+	trial := fixtureSpotify{cost: models.NewMoney(0, "EUR"), postTrial: models.NewMoney(1099, "EUR")}
+	fixtures := []struct {
+		name     string
+		migrated bool
+		spotify  fixtureSpotify
+	}{
+		{"v1.0.json", true, fixtureSpotify{cost: models.NewMoney(1099, "USD"), postTrial: models.NewMoney(0, "USD")}},
+		{"v1.1.json", true, trial},
+		{"v1.2.json", true, trial},
+		{"v1.3.json", false, trial},
+	}
+
+	for _, fixture := range fixtures {
+		t.Run(fixture.name, func(t *testing.T) {
+			path := copyFixture(t, fixture.name)
+			store := NewJSONStorageWithPath(path)
+
+			list, err := store.Load()
+			if err != nil {
+				t.Fatalf("Load: %v", err)
+			}
+			checkFixtureList(t, list, fixture.spotify)
+
+			// The file on disk is rewritten in the current format
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			doc, err := decodeDocument(data)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if doc.version() != dataVersion {
+				t.Errorf("file version = %s, want %s", doc.version(), dataVersion)
+			}
+
+			// The original is backed up before migrating, and only then
+			backups, err := store.ListBackups()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if fixture.migrated != (len(backups) == 1) {
+				t.Fatalf("got %d backups, migrated = %v", len(backups), fixture.migrated)
+			}
+			if fixture.migrated {
+				original, _ := os.ReadFile(filepath.Join("testdata", fixture.name))
+				backup, err := os.ReadFile(backups[0].Path)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if string(backup) != string(original) {
+					t.Error("backup differs from the file before migrating")
+				}
+			}
+
+			// Loading again finds nothing to migrate
+			again, err := store.Load()
+			if err != nil {
+				t.Fatalf("second Load: %v", err)
+			}
+			checkFixtureList(t, again, fixture.spotify)
+			if backups, _ := store.ListBackups(); fixture.migrated != (len(backups) == 1) {
+				t.Errorf("second Load took another backup")
+			}
+		})
+	}
+}
+
+// fixtureSpotify is the Spotify price a fixture should load with
+type fixtureSpotify struct {
+	cost      models.Money
+	postTrial models.Money
+}
+
+// checkFixtureList verifies the content shared by every testdata version
+func checkFixtureList(t *testing.T, list *models.SubscriptionList, want fixtureSpotify) {
+	t.Helper()
+
+	if len(list.Subscriptions) != 2 || len(list.Payments) != 2 {
+		t.Fatalf("got %d subscriptions, %d payments", len(list.Subscriptions), len(list.Payments))
+	}
+
+	netflix, spotify := list.Subscriptions[0], list.Subscriptions[1]
+	if netflix.Cost != models.NewMoney(1599, "USD") {
+		t.Errorf("Netflix cost = %v", netflix.Cost)
+	}
+	if spotify.Cost != want.cost {
+		t.Errorf("Spotify cost = %v", spotify.Cost)
+	}
+	if spotify.PostTrialCost != want.postTrial {
+		t.Errorf("Spotify post-trial cost = %v", spotify.PostTrialCost)
+	}
+
+	generated, manual := list.Payments[0], list.Payments[1]
+	for _, p := range list.Payments {
+		if p.Amount != models.NewMoney(1599, "USD") {
+			t.Errorf("payment %s amount = %v", p.ID, p.Amount)
+		}
+		if p.Type != models.PaymentCharged {
+			t.Errorf("payment %s type = %q", p.ID, p.Type)
+		}
+	}
+	if generated.Source != models.SourceGenerated {
+		t.Errorf("auto-generated payment source = %q", generated.Source)
+	}
+	if manual.Source != models.SourceManual {
+		t.Errorf("manual payment source = %q", manual.Source)
+	}
+}
+
+func TestLoadRefusesNewerVersion(t *testing.T) {
+	path := copyFixture(t, "v9.0.json")
+	before, _ := os.ReadFile(path)
+
+	store := NewJSONStorageWithPath(path)
+	if _, err := store.Load(); !errors.Is(err, ErrNewerVersion) {
+		t.Fatalf("Load error = %v, want ErrNewerVersion", err)
+	}
+
+	after, _ := os.ReadFile(path)
+	if string(after) != string(before) {
+		t.Error("newer file was modified")
+	}
+	if backups, _ := store.ListBackups(); len(backups) != 0 {
+		t.Errorf("got %d backups of a file that wasn't migrated", len(backups))
+	}
+}
+
+func TestMigrateDocumentVersions(t *testing.T) {
+	tests := []struct {
+		version string
+		wantErr error
+	}{
+		{"", nil}, // Unstamped files are the legacy format
+		{"1.0", nil},
+		{"1.2", nil},
+		{"0.9", ErrUnknownVersion},
+		{"1.10", ErrNewerVersion},
+		{"2", ErrNewerVersion},
+	}
+
+	for _, tt := range tests {
+		doc := document{"subscriptions": []any{}, "payments": []any{}}
+		if tt.version != "" {
+			doc["version"] = tt.version
+		}
+
+		_, err := migrateDocument(doc)
+		if !errors.Is(err, tt.wantErr) {
+			t.Errorf("version %q: error = %v, want %v", tt.version, err, tt.wantErr)
+			continue
+		}
+		if err == nil && doc.version() != dataVersion {
+			t.Errorf("version %q: migrated to %s", tt.version, doc.version())
+		}
+	}
+}
+
+func TestMigratePauseHistory(t *testing.T) {
+	doc, err := decodeDocument([]byte(`{
+		"version": "1.2",
+		"subscriptions": [
+			{"id": "edited", "paused": true, "updated_at": "2026-03-20T00:00:00Z"},
+			{"id": "billed", "paused": true, "updated_at": "2026-01-01T00:00:00Z"},
+			{"id": "recorded", "paused": true, "pauses": [{"start": "2026-05-01T00:00:00Z", "resume": "0001-01-01T00:00:00Z"}]},
+			{"id": "active", "paused": false, "updated_at": "2026-03-20T00:00:00Z"}
+		],
+		"payments": [
+			{"subscription_id": "billed", "payment_date": "2026-02-10T00:00:00Z"},
+			{"subscription_id": "billed", "payment_date": "2026-03-10T00:00:00Z"}
+		]
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := migrateDocument(doc); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := json.Marshal(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var list models.SubscriptionList
+	if err := json.Unmarshal(data, &list); err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]string{
+		"edited":   "2026-03-20", // Last edit
+		"billed":   "2026-03-11", // Day after the last payment, which is later than the edit
+		"recorded": "2026-05-01", // Existing history is kept
+	}
+	for _, sub := range list.Subscriptions {
+		start, ok := want[sub.ID]
+		if !ok {
+			if len(sub.Pauses) != 0 {
+				t.Errorf("%s: got pauses %v, want none", sub.ID, sub.Pauses)
+			}
+			continue
+		}
+		if len(sub.Pauses) != 1 {
+			t.Fatalf("%s: got %d pauses, want 1", sub.ID, len(sub.Pauses))
+		}
+		pause := sub.Pauses[0]
+		if got := pause.Start.Format("2006-01-02"); got != start || !pause.Resume.IsZero() {
+			t.Errorf("%s: pause = %v, want open pause from %s", sub.ID, pause, start)
+		}
+	}
+}
+
+func TestMigrateMoneyObjectsIsExact(t *testing.T) {
+	doc, err := decodeDocument([]byte(`{"version": "1.0", "payments": [{"amount": 0.29, "currency": "USD"}, {"amount": 1500, "currency": "JPY"}]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := migrateDocument(doc); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := json.Marshal(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var list models.SubscriptionList
+	if err := json.Unmarshal(data, &list); err != nil {
+		t.Fatal(err)
+	}
+	if got := list.Payments[0].Amount; got != models.NewMoney(29, "USD") {
+		t.Errorf("0.29 USD migrated to %v", got)
+	}
+	if got := list.Payments[1].Amount; got != models.NewMoney(1500, "JPY") {
+		t.Errorf("1500 JPY migrated to %v", got)
+	}
+}
+
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"1.0", "1.0", 0},
+		{"1", "1.0", 0},
+		{"1.0", "1.1", -1},
+		{"1.10", "1.9", 1},
+		{"2.0", "1.12", 1},
+	}
+	for _, tt := range tests {
+		if got := compareVersions(tt.a, tt.b); got != tt.want {
+			t.Errorf("compareVersions(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
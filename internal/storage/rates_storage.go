@@ -0,0 +1,70 @@
+package storage
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"subman/internal/models"
+)
+
+const defaultRatesFileName = "exchange_rates.json"
+
+// RatesStorage persists the exchange-rate table next to the subscriptions file
+type RatesStorage struct {
+	filePath string
+	mu       sync.RWMutex
+}
+
+// NewRatesStorage creates rates storage in the same directory as the given data file
+func NewRatesStorage(dataPath string) *RatesStorage {
+	return &RatesStorage{
+		filePath: filepath.Join(filepath.Dir(dataPath), defaultRatesFileName),
+	}
+}
+
+// Load reads the exchange-rate table, returning an empty USD table if none exists
+func (s *RatesStorage) Load() (*models.ExchangeRates, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	rates := &models.ExchangeRates{
+		Base:  models.DefaultCurrency,
+		Rates: map[string]float64{},
+	}
+
+	data, err := os.ReadFile(s.filePath)
+	if os.IsNotExist(err) {
+		return rates, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(data, rates); err != nil {
+		return nil, err
+	}
+	if rates.Rates == nil {
+		rates.Rates = map[string]float64{}
+	}
+
+	return rates, nil
+}
+
+// Save writes the exchange-rate table
+func (s *RatesStorage) Save(rates *models.ExchangeRates) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	data, err := json.MarshalIndent(rates, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return writeFileAtomic(s.filePath, data, 0600)
+}
+
+func (s *RatesStorage) GetPath() string {
+	return s.filePath
+}
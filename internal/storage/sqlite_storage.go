@@ -0,0 +1,547 @@
+package storage
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+
+	// Pure-Go SQLite driver, registered as "sqlite"; needs no C toolchain
+	_ "github.com/glebarez/go-sqlite"
+
+	"subman/internal/models"
+	"subman/pkg/vault"
+)
+
+const (
+	defaultDBFileName = "subscriptions.db"
+
+	// migratedSuffix is added to the JSON data file once it is copied into SQLite
+	migratedSuffix = ".migrated"
+)
+
+// ErrEncryptedMigration is returned when migrating an encrypted JSON data file
+// into SQLite, which would store it unencrypted
+var ErrEncryptedMigration = errors.New("the data file is encrypted and SQLite storage can't be; " +
+	"turn off encryption before switching to SQLite, or keep JSON storage")
+
+// sqliteDriver is the database/sql driver name
+const sqliteDriver = "sqlite"
+
+const sqliteSchema = `
+CREATE TABLE IF NOT EXISTS settings (
+	key   TEXT PRIMARY KEY,
+	value TEXT NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS subscriptions (
+	id                  TEXT PRIMARY KEY,
+	name                TEXT NOT NULL,
+	cost_amount         INTEGER NOT NULL,
+	cost_currency       TEXT NOT NULL,
+	billing_cycle       TEXT NOT NULL,
+	custom_interval     TEXT,
+	next_payment        TEXT NOT NULL,
+	start_date          TEXT NOT NULL,
+	trial_end_date      TEXT NOT NULL,
+	post_trial_amount   INTEGER NOT NULL,
+	post_trial_currency TEXT NOT NULL,
+	price_history       TEXT,
+	category            TEXT NOT NULL,
+	tags                TEXT,
+	payment_method_id   TEXT NOT NULL,
+	sharing             TEXT,
+	notes               TEXT NOT NULL,
+	image               TEXT NOT NULL,
+	paused              INTEGER NOT NULL,
+	skipped_payments    TEXT,
+	pauses              TEXT,
+	cancelled_at        TEXT NOT NULL,
+	access_ends_at      TEXT NOT NULL,
+	deleted             INTEGER NOT NULL,
+	deleted_at          TEXT NOT NULL,
+	created_at          TEXT NOT NULL,
+	updated_at          TEXT NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS payments (
+	id                TEXT PRIMARY KEY,
+	subscription_id   TEXT NOT NULL,
+	amount            INTEGER NOT NULL,
+	currency          TEXT NOT NULL,
+	type              TEXT NOT NULL,
+	payment_date      TEXT NOT NULL,
+	notes             TEXT NOT NULL,
+	payment_method_id TEXT NOT NULL,
+	source            TEXT NOT NULL,
+	created_at        TEXT NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS payments_subscription ON payments (subscription_id, payment_date);
+`
+
+const subscriptionColumns = `id, name, cost_amount, cost_currency, billing_cycle, custom_interval,
+	next_payment, start_date, trial_end_date, post_trial_amount, post_trial_currency, price_history,
+	category, tags, payment_method_id, sharing, notes, image, paused, skipped_payments, pauses,
+	cancelled_at, access_ends_at, deleted, deleted_at, created_at, updated_at`
+
+const paymentColumns = `id, subscription_id, amount, currency, type, payment_date, notes,
+	payment_method_id, source, created_at`
+
+// Keys of the settings table; registries are stored as JSON documents
+const (
+	settingVersion         = "version"
+	settingCategories      = "categories"
+	settingPaymentMethods  = "payment_methods"
+	settingBudgets         = "budgets"
+	settingFiscalYearStart = "fiscal_year_start"
+)
+
+// SQLiteStorage keeps subscriptions and payments in a SQLite database
+// Save only writes the rows that changed since the last Load or Save.
+type SQLiteStorage struct {
+	filePath string
+	db       *sql.DB
+	mu       sync.Mutex
+
+	// Encoded rows as last read or written, by ID, to find what Save must change
+	subscriptionRows map[string]string
+	paymentRows      map[string]string
+}
+
+// NewSQLiteStorage opens (or creates) the database in the user's config directory
+func NewSQLiteStorage() (*SQLiteStorage, error) {
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return nil, err
+	}
+
+	submanDir := filepath.Join(configDir, "subman")
+	if err := os.MkdirAll(submanDir, 0700); err != nil {
+		return nil, err
+	}
+
+	return NewSQLiteStorageWithPath(filepath.Join(submanDir, defaultDBFileName))
+}
+
+// NewSQLiteStorageWithPath opens (or creates) the database at a specific path
+func NewSQLiteStorageWithPath(path string) (*SQLiteStorage, error) {
+	db, err := sql.Open(sqliteDriver, path)
+	if err != nil {
+		return nil, err
+	}
+	// SQLite allows one writer; a single connection avoids "database is locked"
+	db.SetMaxOpenConns(1)
+
+	if _, err := db.Exec(sqliteSchema); err != nil {
+		db.Close()
+		return nil, err
+	}
+	if err := os.Chmod(path, 0600); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return &SQLiteStorage{
+		filePath: path,
+		db:       db,
+	}, nil
+}
+
+// Close closes the database
+func (s *SQLiteStorage) Close() error {
+	return s.db.Close()
+}
+
+// IsEmpty reports whether nothing has been saved to the database yet
+func (s *SQLiteStorage) IsEmpty() (bool, error) {
+	var count int
+	err := s.db.QueryRow(`SELECT COUNT(*) FROM settings WHERE key = ?`, settingVersion).Scan(&count)
+	return count == 0, err
+}
+
+func (s *SQLiteStorage) Load() (*models.SubscriptionList, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.load()
+}
+
+func (s *SQLiteStorage) Save(list *models.SubscriptionList) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Without a snapshot Save can't tell which rows were removed
+	if s.subscriptionRows == nil {
+		if _, err := s.load(); err != nil {
+			return err
+		}
+	}
+
+	// A newer version may have saved since, with data this one would drop
+	if err := checkStoredVersion(s.db); err != nil {
+		return err
+	}
+
+	list.Version = dataVersion
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	subscriptionRows, err := saveRows(tx, "subscriptions", list.Subscriptions, s.subscriptionRows,
+		func(sub models.Subscription) string { return sub.ID }, insertSubscription)
+	if err != nil {
+		return err
+	}
+
+	paymentRows, err := saveRows(tx, "payments", list.Payments, s.paymentRows,
+		func(payment models.Payment) string { return payment.ID }, insertPayment)
+	if err != nil {
+		return err
+	}
+
+	if err := saveSettings(tx, list); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	s.subscriptionRows = subscriptionRows
+	s.paymentRows = paymentRows
+	return nil
+}
+
+func (s *SQLiteStorage) GetPath() string {
+	return s.filePath
+}
+
+func (s *SQLiteStorage) load() (*models.SubscriptionList, error) {
+	if err := checkStoredVersion(s.db); err != nil {
+		return nil, err
+	}
+
+	list := &models.SubscriptionList{
+		Subscriptions: []models.Subscription{},
+		Version:       dataVersion,
+	}
+
+	subs, subscriptionRows, err := loadSubscriptions(s.db)
+	if err != nil {
+		return nil, err
+	}
+	payments, paymentRows, err := loadPayments(s.db)
+	if err != nil {
+		return nil, err
+	}
+	list.Subscriptions = append(list.Subscriptions, subs...)
+	list.Payments = payments
+
+	if err := loadSettings(s.db, list); err != nil {
+		return nil, err
+	}
+
+	s.subscriptionRows = subscriptionRows
+	s.paymentRows = paymentRows
+	return list, nil
+}
+
+// checkStoredVersion returns ErrNewerVersion if the database was saved by a
+// newer version of subman, whose fields this version would lose on saving
+func checkStoredVersion(db *sql.DB) error {
+	var version string
+	err := db.QueryRow(`SELECT value FROM settings WHERE key = ?`, settingVersion).Scan(&version)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return checkVersion(version)
+}
+
+// saveRows inserts or replaces the records that differ from the snapshot and
+// deletes the ones that are gone, returning the new snapshot
+func saveRows[T any](tx *sql.Tx, table string, records []T, snapshot map[string]string, id func(T) string, insert func(*sql.Tx, T) error) (map[string]string, error) {
+	rows := make(map[string]string, len(records))
+	for _, record := range records {
+		encoded, err := json.Marshal(record)
+		if err != nil {
+			return nil, err
+		}
+		key := id(record)
+		rows[key] = string(encoded)
+
+		if previous, ok := snapshot[key]; ok && previous == rows[key] {
+			continue
+		}
+		if err := insert(tx, record); err != nil {
+			return nil, err
+		}
+	}
+
+	for key := range snapshot {
+		if _, ok := rows[key]; ok {
+			continue
+		}
+		if _, err := tx.Exec(`DELETE FROM `+table+` WHERE id = ?`, key); err != nil {
+			return nil, err
+		}
+	}
+
+	return rows, nil
+}
+
+func insertSubscription(tx *sql.Tx, sub models.Subscription) error {
+	_, err := tx.Exec(`INSERT OR REPLACE INTO subscriptions (`+subscriptionColumns+`)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		sub.ID, sub.Name, sub.Cost.Amount, sub.Cost.Currency, string(sub.BillingCycle), jsonColumn(sub.CustomInterval),
+		timeColumn(sub.NextPayment), timeColumn(sub.StartDate), timeColumn(sub.TrialEndDate),
+		sub.PostTrialCost.Amount, sub.PostTrialCost.Currency, jsonColumn(sub.PriceHistory),
+		string(sub.Category), jsonColumn(sub.Tags), sub.PaymentMethodID, jsonColumn(sub.Sharing),
+		sub.Notes, sub.Image, sub.Paused, jsonColumn(sub.SkippedPayments), jsonColumn(sub.Pauses),
+		timeColumn(sub.CancelledAt), timeColumn(sub.AccessEndsAt), sub.Deleted, timeColumn(sub.DeletedAt),
+		timeColumn(sub.CreatedAt), timeColumn(sub.UpdatedAt))
+	return err
+}
+
+func insertPayment(tx *sql.Tx, payment models.Payment) error {
+	_, err := tx.Exec(`INSERT OR REPLACE INTO payments (`+paymentColumns+`)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		payment.ID, payment.SubscriptionID, payment.Amount.Amount, payment.Amount.Currency, string(payment.Type),
+		timeColumn(payment.PaymentDate), payment.Notes, payment.PaymentMethodID, string(payment.Source),
+		timeColumn(payment.CreatedAt))
+	return err
+}
+
+func loadSubscriptions(db *sql.DB) ([]models.Subscription, map[string]string, error) {
+	rows, err := db.Query(`SELECT ` + subscriptionColumns + ` FROM subscriptions ORDER BY created_at, id`)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	var subs []models.Subscription
+	snapshot := make(map[string]string)
+	for rows.Next() {
+		var sub models.Subscription
+		var cycle, category string
+		var customInterval, priceHistory, tags, sharing, skipped, pauses sql.NullString
+		var nextPayment, startDate, trialEnd, cancelledAt, accessEndsAt, deletedAt, createdAt, updatedAt string
+
+		if err := rows.Scan(&sub.ID, &sub.Name, &sub.Cost.Amount, &sub.Cost.Currency, &cycle, &customInterval,
+			&nextPayment, &startDate, &trialEnd, &sub.PostTrialCost.Amount, &sub.PostTrialCost.Currency, &priceHistory,
+			&category, &tags, &sub.PaymentMethodID, &sharing, &sub.Notes, &sub.Image, &sub.Paused, &skipped, &pauses,
+			&cancelledAt, &accessEndsAt, &sub.Deleted, &deletedAt, &createdAt, &updatedAt); err != nil {
+			return nil, nil, err
+		}
+
+		sub.BillingCycle = models.BillingCycle(cycle)
+		sub.Category = models.Category(category)
+
+		for _, column := range []struct {
+			value  sql.NullString
+			target any
+		}{
+			{customInterval, &sub.CustomInterval},
+			{priceHistory, &sub.PriceHistory},
+			{tags, &sub.Tags},
+			{sharing, &sub.Sharing},
+			{skipped, &sub.SkippedPayments},
+			{pauses, &sub.Pauses},
+		} {
+			if column.value.Valid {
+				if err := json.Unmarshal([]byte(column.value.String), column.target); err != nil {
+					return nil, nil, err
+				}
+			}
+		}
+
+		for _, column := range []struct {
+			value  string
+			target *time.Time
+		}{
+			{nextPayment, &sub.NextPayment},
+			{startDate, &sub.StartDate},
+			{trialEnd, &sub.TrialEndDate},
+			{cancelledAt, &sub.CancelledAt},
+			{accessEndsAt, &sub.AccessEndsAt},
+			{deletedAt, &sub.DeletedAt},
+			{createdAt, &sub.CreatedAt},
+			{updatedAt, &sub.UpdatedAt},
+		} {
+			if *column.target, err = parseTimeColumn(column.value); err != nil {
+				return nil, nil, err
+			}
+		}
+
+		encoded, err := json.Marshal(sub)
+		if err != nil {
+			return nil, nil, err
+		}
+		snapshot[sub.ID] = string(encoded)
+		subs = append(subs, sub)
+	}
+
+	return subs, snapshot, rows.Err()
+}
+
+func loadPayments(db *sql.DB) ([]models.Payment, map[string]string, error) {
+	rows, err := db.Query(`SELECT ` + paymentColumns + ` FROM payments ORDER BY payment_date, id`)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	var payments []models.Payment
+	snapshot := make(map[string]string)
+	for rows.Next() {
+		var payment models.Payment
+		var paymentType, source, paymentDate, createdAt string
+
+		if err := rows.Scan(&payment.ID, &payment.SubscriptionID, &payment.Amount.Amount, &payment.Amount.Currency,
+			&paymentType, &paymentDate, &payment.Notes, &payment.PaymentMethodID, &source, &createdAt); err != nil {
+			return nil, nil, err
+		}
+
+		payment.Type = models.PaymentType(paymentType)
+		payment.Source = models.PaymentSource(source)
+		if payment.PaymentDate, err = parseTimeColumn(paymentDate); err != nil {
+			return nil, nil, err
+		}
+		if payment.CreatedAt, err = parseTimeColumn(createdAt); err != nil {
+			return nil, nil, err
+		}
+
+		encoded, err := json.Marshal(payment)
+		if err != nil {
+			return nil, nil, err
+		}
+		snapshot[payment.ID] = string(encoded)
+		payments = append(payments, payment)
+	}
+
+	return payments, snapshot, rows.Err()
+}
+
+func saveSettings(tx *sql.Tx, list *models.SubscriptionList) error {
+	settings := map[string]any{
+		settingCategories:     list.Categories,
+		settingPaymentMethods: list.PaymentMethods,
+		settingBudgets:        list.Budgets,
+	}
+
+	values := map[string]string{
+		settingVersion:         list.Version,
+		settingFiscalYearStart: strconv.Itoa(int(list.FiscalYearMonth)),
+	}
+	for key, value := range settings {
+		encoded, err := json.Marshal(value)
+		if err != nil {
+			return err
+		}
+		values[key] = string(encoded)
+	}
+
+	for key, value := range values {
+		if _, err := tx.Exec(`INSERT OR REPLACE INTO settings (key, value) VALUES (?, ?)`, key, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func loadSettings(db *sql.DB, list *models.SubscriptionList) error {
+	rows, err := db.Query(`SELECT key, value FROM settings`)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var key, value string
+		if err := rows.Scan(&key, &value); err != nil {
+			return err
+		}
+
+		switch key {
+		case settingVersion:
+			list.Version = value
+		case settingFiscalYearStart:
+			month, err := strconv.Atoi(value)
+			if err != nil {
+				return err
+			}
+			list.FiscalYearMonth = time.Month(month)
+		case settingCategories:
+			err = json.Unmarshal([]byte(value), &list.Categories)
+		case settingPaymentMethods:
+			err = json.Unmarshal([]byte(value), &list.PaymentMethods)
+		case settingBudgets:
+			err = json.Unmarshal([]byte(value), &list.Budgets)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
+
+// jsonColumn encodes a nested value for a TEXT column, NULL when empty
+func jsonColumn(value any) sql.NullString {
+	encoded, err := json.Marshal(value)
+	if err != nil || string(encoded) == "null" || string(encoded) == "[]" {
+		return sql.NullString{}
+	}
+	return sql.NullString{String: string(encoded), Valid: true}
+}
+
+// timeColumn formats a time for a TEXT column the way the JSON file stores it
+func timeColumn(t time.Time) string {
+	return t.Format(time.RFC3339Nano)
+}
+
+func parseTimeColumn(value string) (time.Time, error) {
+	return time.Parse(time.RFC3339Nano, value)
+}
+
+// MigrateJSONToSQLite copies the JSON data file into an empty database once,
+// then renames the file to <name>.migrated so it isn't imported again
+// It reports whether anything was migrated. An encrypted data file is refused
+// with ErrEncryptedMigration rather than written to the database unencrypted.
+func MigrateJSONToSQLite(jsonPath string, db *SQLiteStorage) (bool, error) {
+	data, err := os.ReadFile(jsonPath)
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	empty, err := db.IsEmpty()
+	if err != nil || !empty {
+		return false, err
+	}
+	if vault.IsSealed(data) {
+		return false, ErrEncryptedMigration
+	}
+
+	list, err := NewJSONStorageWithPath(jsonPath).Load()
+	if err != nil {
+		return false, err
+	}
+	if err := db.Save(list); err != nil {
+		return false, err
+	}
+
+	return true, os.Rename(jsonPath, jsonPath+migratedSuffix)
+}
@@ -0,0 +1,121 @@
+package storage
+
+import (
+	"encoding/json"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"subman/internal/models"
+)
+
+// openSQLite opens the database at path, closing it when the test ends
+func openSQLite(t *testing.T, path string) *SQLiteStorage {
+	t.Helper()
+
+	store, err := NewSQLiteStorageWithPath(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { store.Close() })
+	return store
+}
+
+// loadJSON loads the list from a fresh connection and encodes it for comparison
+func loadJSON(t *testing.T, path string) string {
+	t.Helper()
+
+	list, err := openSQLite(t, path).Load()
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	data, err := json.Marshal(list)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestSQLiteSaveLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "subscriptions.db")
+	store := openSQLite(t, path)
+
+	created := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	day := time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC)
+	list := &models.SubscriptionList{
+		Subscriptions: []models.Subscription{
+			{ID: "a", Name: "Netflix", Cost: models.NewMoney(1599, "USD"), BillingCycle: models.Monthly,
+				StartDate: day, NextPayment: day.AddDate(0, 1, 0), Tags: []string{"tv"}, CreatedAt: created, UpdatedAt: created},
+			{ID: "b", Name: "Spotify", Cost: models.NewMoney(1099, "EUR"), BillingCycle: models.Yearly,
+				StartDate: day, NextPayment: day.AddDate(1, 0, 0), CreatedAt: created.Add(time.Hour), UpdatedAt: created},
+			{ID: "c", Name: "Dropbox", Cost: models.NewMoney(999, "USD"), BillingCycle: models.Monthly,
+				StartDate: day, NextPayment: day.AddDate(0, 1, 0), CreatedAt: created.Add(2 * time.Hour), UpdatedAt: created},
+		},
+		Payments: []models.Payment{
+			{ID: "p1", SubscriptionID: "a", Amount: models.NewMoney(1599, "USD"), Type: models.PaymentCharged,
+				PaymentDate: day, Source: models.SourceGenerated, CreatedAt: created},
+			{ID: "p2", SubscriptionID: "c", Amount: models.NewMoney(999, "USD"), Type: models.PaymentCharged,
+				PaymentDate: day, Source: models.SourceManual, CreatedAt: created},
+		},
+		FiscalYearMonth: time.April,
+	}
+	if err := store.Save(list); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	want, _ := json.Marshal(list)
+	if got := loadJSON(t, path); got != string(want) {
+		t.Fatalf("loaded\n%s\nwant\n%s", got, want)
+	}
+
+	// Count the rows the next save writes
+	if _, err := store.db.Exec(`CREATE TABLE writes (tbl TEXT);
+		CREATE TRIGGER subscription_writes AFTER INSERT ON subscriptions BEGIN INSERT INTO writes VALUES ('subscriptions'); END;
+		CREATE TRIGGER payment_writes AFTER INSERT ON payments BEGIN INSERT INTO writes VALUES ('payments'); END;`); err != nil {
+		t.Fatal(err)
+	}
+
+	// Rename one subscription, delete another with its payment, and add a payment
+	list.Subscriptions[1].Name = "Spotify Family"
+	list.Subscriptions = list.Subscriptions[:2]
+	list.Payments = []models.Payment{list.Payments[0], {ID: "p3", SubscriptionID: "b", Amount: models.NewMoney(1099, "EUR"),
+		Type: models.PaymentCharged, PaymentDate: day, Source: models.SourceManual, CreatedAt: created}}
+	if err := store.Save(list); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	want, _ = json.Marshal(list)
+	if got := loadJSON(t, path); got != string(want) {
+		t.Fatalf("loaded\n%s\nwant\n%s", got, want)
+	}
+
+	// Only the changed rows were written
+	for table, n := range map[string]int{"subscriptions": 1, "payments": 1} {
+		var count int
+		if err := store.db.QueryRow(`SELECT COUNT(*) FROM writes WHERE tbl = ?`, table).Scan(&count); err != nil {
+			t.Fatal(err)
+		}
+		if count != n {
+			t.Errorf("second save wrote %d %s rows, want %d", count, table, n)
+		}
+	}
+}
+
+func TestSQLiteRefusesNewerVersion(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "subscriptions.db")
+	store := openSQLite(t, path)
+	if err := store.Save(&models.SubscriptionList{}); err != nil {
+		t.Fatal(err)
+	}
+
+	// A newer version of subman saves in the meantime
+	if _, err := store.db.Exec(`UPDATE settings SET value = '9.0' WHERE key = ?`, settingVersion); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := store.Save(&models.SubscriptionList{}); !errors.Is(err, ErrNewerVersion) {
+		t.Errorf("Save: error = %v, want ErrNewerVersion", err)
+	}
+	if _, err := openSQLite(t, path).Load(); !errors.Is(err, ErrNewerVersion) {
+		t.Errorf("Load: error = %v, want ErrNewerVersion", err)
+	}
+}
@@ -0,0 +1,229 @@
+package storage
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// watchDelay lets editors and sync tools finish writing before the file is checked
+const watchDelay = 250 * time.Millisecond
+
+// ErrModifiedExternally is returned when saving over a data file another program changed
+var ErrModifiedExternally = errors.New("the data file was changed by another program since it was loaded; " +
+	"your change was not saved so it doesn't overwrite theirs")
+
+// WatchedStorage is a Storage that notices changes other programs make to its data
+type WatchedStorage interface {
+	Storage
+
+	// Watch calls onChange, from another goroutine, whenever another program
+	// changes the data
+	Watch(onChange func()) error
+
+	// AcceptChanges takes the data as it is now as the version the app shows,
+	// after it reloaded another program's changes; until then saves fail with
+	// ErrModifiedExternally
+	AcceptChanges() error
+
+	// Close stops watching
+	Close() error
+}
+
+// fileState identifies the contents of the data file as last read or written
+type fileState struct {
+	exists  bool
+	size    int64
+	modTime time.Time
+	hash    [sha256.Size]byte
+}
+
+// readFileState returns the state and contents of the file at path
+func readFileState(path string) (fileState, []byte, error) {
+	info, err := os.Stat(path)
+	if os.IsNotExist(err) {
+		return fileState{}, nil, nil
+	}
+	if err != nil {
+		return fileState{}, nil, err
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fileState{}, nil, err
+	}
+	return fileState{
+		exists:  true,
+		size:    info.Size(),
+		modTime: info.ModTime(),
+		hash:    sha256.Sum256(data),
+	}, data, nil
+}
+
+// sameFile reports whether the file at path still has the state known
+// The modification time and size are compared first; the contents are only
+// hashed when they differ, so touching the file doesn't count as a change.
+func sameFile(path string, known fileState) (bool, error) {
+	info, err := os.Stat(path)
+	if os.IsNotExist(err) {
+		return !known.exists, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if !known.exists {
+		return false, nil
+	}
+	if info.Size() == known.size && info.ModTime().Equal(known.modTime) {
+		return true, nil
+	}
+
+	current, _, err := readFileState(path)
+	if err != nil {
+		return false, err
+	}
+	return bytes.Equal(current.hash[:], known.hash[:]), nil
+}
+
+// readData reads the data file; nil data means there is no file yet
+// The first read is remembered as the version the app shows. Later reads are
+// not: the app keeps showing what it loaded until it accepts another
+// program's changes with AcceptChanges, so a save in between is refused.
+func (s *JSONStorage) readData() ([]byte, error) {
+	state, data, err := readFileState(s.filePath)
+	if err != nil {
+		return nil, err
+	}
+	if !s.tracking {
+		s.known = state
+		s.tracking = true
+	}
+	return data, nil
+}
+
+// track remembers the data file as it is now as the version the app shows
+func (s *JSONStorage) track() error {
+	state, _, err := readFileState(s.filePath)
+	if err != nil {
+		return err
+	}
+	s.known = state
+	s.tracking = true
+	return nil
+}
+
+// AcceptChanges takes the data file as it is now, changes by other programs
+// included, as the version the app shows; call it once the app has reloaded
+func (s *JSONStorage) AcceptChanges() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.track()
+}
+
+// checkUnchanged returns ErrModifiedExternally if the data file changed since
+// this storage first read it, last wrote it or accepted its changes
+func (s *JSONStorage) checkUnchanged() error {
+	if !s.tracking {
+		return nil
+	}
+	same, err := sameFile(s.filePath, s.known)
+	if err != nil {
+		return err
+	}
+	if !same {
+		return ErrModifiedExternally
+	}
+	return nil
+}
+
+// Watch calls onChange when another program changes the data file; saves made
+// through this storage don't trigger it
+func (s *JSONStorage) Watch(onChange func()) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+
+	// Watch the folder: atomic saves, ours and other programs', replace the
+	// file rather than write to it, which would end a watch on the file itself
+	if err := watcher.Add(filepath.Dir(s.filePath)); err != nil {
+		watcher.Close()
+		return err
+	}
+
+	s.mu.Lock()
+	if s.watcher != nil {
+		s.watcher.Close()
+	}
+	s.watcher = watcher
+	s.mu.Unlock()
+
+	go s.watch(watcher, onChange)
+	return nil
+}
+
+// Close stops watching the data file
+func (s *JSONStorage) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.watcher == nil {
+		return nil
+	}
+	err := s.watcher.Close()
+	s.watcher = nil
+	return err
+}
+
+func (s *JSONStorage) watch(watcher *fsnotify.Watcher, onChange func()) {
+	name := filepath.Base(s.filePath)
+
+	var pending *time.Timer
+	check := func() {
+		if pending != nil {
+			pending.Stop()
+		}
+		pending = time.AfterFunc(watchDelay, func() {
+			if s.changedExternally() {
+				onChange()
+			}
+		})
+	}
+
+	for {
+		select {
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return
+			}
+			if filepath.Base(event.Name) == name {
+				check()
+			}
+		case _, ok := <-watcher.Errors:
+			if !ok {
+				return
+			}
+			// Events may have been dropped, so look at the file anyway
+			check()
+		}
+	}
+}
+
+// changedExternally reports whether the data file differs from the version the
+// app shows
+func (s *JSONStorage) changedExternally() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if !s.tracking {
+		return false
+	}
+	same, err := sameFile(s.filePath, s.known)
+	return err == nil && !same
+}
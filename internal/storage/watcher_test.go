@@ -0,0 +1,142 @@
+package storage
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"subman/internal/models"
+)
+
+// writeExternally changes the data file the way another program would
+func writeExternally(t *testing.T, path, name string) {
+	t.Helper()
+
+	data := `{"version": "` + dataVersion + `", "subscriptions": [{"id": "x", "name": "` + name + `"}], "payments": []}`
+	if err := os.WriteFile(path, []byte(data), 0600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSaveRefusesExternalChanges(t *testing.T) {
+	path := filepath.Join(t.TempDir(), defaultFileName)
+	store := NewJSONStorageWithPath(path)
+	saveSubscriptions(t, store, 1)
+
+	list, err := store.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeExternally(t, path, "Theirs")
+
+	// Loading again doesn't make the change the version the app shows
+	if _, err := store.Load(); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Save(list); !errors.Is(err, ErrModifiedExternally) {
+		t.Fatalf("Save over an outside change: error = %v, want ErrModifiedExternally", err)
+	}
+	if list, _ := store.Load(); len(list.Subscriptions) != 1 || list.Subscriptions[0].Name != "Theirs" {
+		t.Fatal("refused save changed the file")
+	}
+
+	// Once the app has reloaded, saving works again
+	if err := store.AcceptChanges(); err != nil {
+		t.Fatal(err)
+	}
+	list, err = store.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	list.Subscriptions[0].Name = "Mine"
+	if err := store.Save(list); err != nil {
+		t.Fatalf("Save after accepting the change: %v", err)
+	}
+
+	// Our own saves, one after another, are never taken for outside changes
+	saveSubscriptions(t, store, 2)
+	saveSubscriptions(t, store, 3)
+}
+
+func TestSaveIgnoresTouchedFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), defaultFileName)
+	store := NewJSONStorageWithPath(path)
+	saveSubscriptions(t, store, 1)
+
+	// A newer modification time with the same contents is not a change
+	later := time.Now().Add(time.Hour)
+	if err := os.Chtimes(path, later, later); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Save(&models.SubscriptionList{}); err != nil {
+		t.Fatalf("Save after the file was touched: %v", err)
+	}
+}
+
+func TestSaveRefusesDeletedFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), defaultFileName)
+	store := NewJSONStorageWithPath(path)
+	saveSubscriptions(t, store, 1)
+
+	if err := os.Remove(path); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Save(&models.SubscriptionList{}); !errors.Is(err, ErrModifiedExternally) {
+		t.Fatalf("Save after the file was deleted: error = %v, want ErrModifiedExternally", err)
+	}
+}
+
+func TestWatch(t *testing.T) {
+	path := filepath.Join(t.TempDir(), defaultFileName)
+	store := NewJSONStorageWithPath(path)
+	saveSubscriptions(t, store, 1)
+
+	var changes atomic.Int32
+	if err := store.Watch(func() { changes.Add(1) }); err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+
+	// Our own saves don't count
+	saveSubscriptions(t, store, 2)
+	time.Sleep(3 * watchDelay)
+	if n := changes.Load(); n != 0 {
+		t.Fatalf("own save reported %d times", n)
+	}
+
+	// A burst of writes by another program is reported once, after it settles
+	for i := 0; i < 5; i++ {
+		writeExternally(t, path, "Theirs")
+		time.Sleep(watchDelay / 10)
+	}
+	if n := changes.Load(); n != 0 {
+		t.Fatalf("reported %d times before the writes settled", n)
+	}
+	time.Sleep(3 * watchDelay)
+	if n := changes.Load(); n != 1 {
+		t.Fatalf("burst of writes reported %d times, want 1", n)
+	}
+
+	// Once accepted, the same contents are no longer a change
+	if err := store.AcceptChanges(); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(path, time.Now(), time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(3 * watchDelay)
+	if n := changes.Load(); n != 1 {
+		t.Errorf("touching the accepted file reported a change")
+	}
+
+	// Nothing is reported after Close
+	store.Close()
+	writeExternally(t, path, "Later")
+	time.Sleep(3 * watchDelay)
+	if n := changes.Load(); n != 1 {
+		t.Errorf("reported a change after Close")
+	}
+}
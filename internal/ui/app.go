@@ -1,6 +1,7 @@
 package ui
 
 import (
+	"fmt"
 	"log"
 
 	"fyne.io/fyne/v2"
@@ -13,67 +14,108 @@ import (
 )
 
 type App struct {
-	fyneApp        fyne.App
-	window         fyne.Window
-	service        *service.SubscriptionService
-	paymentService *service.PaymentService
+	fyneApp         fyne.App
+	window          fyne.Window
+	service         *service.SubscriptionService
+	paymentService  *service.PaymentService
+	categoryService *service.CategoryService
+	methodService   *service.PaymentMethodService
+	budgetService   *service.BudgetService
 
 	// Views
+	unlock     *UnlockView // Shown instead of the main views until encrypted data is unlocked
 	dashboard  *DashboardView
 	listView   *ListView
 	filterView *FilterView
 }
 
-func NewApp(service *service.SubscriptionService, paymentService *service.PaymentService) *App {
+func NewApp(service *service.SubscriptionService, paymentService *service.PaymentService, categoryService *service.CategoryService, methodService *service.PaymentMethodService, budgetService *service.BudgetService) *App {
 	fyneApp := app.NewWithID("com.subman.app")
 	window := fyneApp.NewWindow("Subman - Subscription Manager")
 
 	a := &App{
-		fyneApp:        fyneApp,
-		window:         window,
-		service:        service,
-		paymentService: paymentService,
+		fyneApp:         fyneApp,
+		window:          window,
+		service:         service,
+		paymentService:  paymentService,
+		categoryService: categoryService,
+		methodService:   methodService,
+		budgetService:   budgetService,
 	}
 
+	// Load saved theme preference
+	a.loadThemePreference()
+
+	// Encrypted data can't be read until the passphrase is entered
+	if a.service.IsLocked() {
+		a.unlock = NewUnlockView(a)
+		return a
+	}
+
+	a.start()
+	return a
+}
+
+// start builds the views and brings the data up to date
+func (a *App) start() {
 	a.dashboard = NewDashboardView(a)
 	a.listView = NewListView(a)
 	a.filterView = NewFilterView(a)
 
-	// Initialize default category icons
-	if err := images.EnsureDefaultCategoryIcons(); err != nil {
-		log.Printf("Warning: Failed to create default category icons: %v", err)
+	// Set up the category registry and its default icons
+	if err := a.prepareCategories(); err != nil {
+		log.Printf("Warning: %v", err)
 	}
 
-	// Generate payments for all active subscriptions
-	if err := paymentService.GenerateAllPayments(); err != nil {
-		log.Printf("Warning: Failed to generate payment history: %v", err)
+	// Permanently remove subscriptions that have been in the trash too long
+	if err := a.autoPurgeTrash(); err != nil {
+		log.Printf("Warning: Failed to purge trash: %v", err)
 	}
 
-	// Load saved theme preference
-	a.loadThemePreference()
+	// Bring trials, prices, pauses and payment history up to date
+	if err := a.applyScheduledChanges(); err != nil {
+		log.Printf("Warning: %v", err)
+	}
+
+	// Show changes made to the data file by other programs, e.g. a sync tool
+	if err := a.service.WatchStorage(func() { fyne.Do(a.reload) }); err != nil {
+		log.Printf("Warning: Failed to watch for outside changes: %v", err)
+	}
 
 	// Setup menu
 	a.setupMenu()
+}
 
-	return a
+// unlocked starts the app once the passphrase has been entered
+func (a *App) unlocked() {
+	a.unlock = nil
+	a.start()
+	a.window.SetContent(a.mainContent())
 }
 
 func (a *App) Run() {
-	// Setup main layout
-	content := container.NewBorder(
+	if a.unlock != nil {
+		a.window.SetContent(a.unlock.Render())
+	} else {
+		a.window.SetContent(a.mainContent())
+	}
+
+	a.window.Resize(fyne.NewSize(1000, 700))
+	a.window.ShowAndRun()
+}
+
+// mainContent lays out the dashboard, filters and list
+func (a *App) mainContent() fyne.CanvasObject {
+	return container.NewBorder(
 		a.dashboard.Render(), // Top - dashboard with stats
-		nil,                   // Bottom
-		nil,                   // Left
-		nil,                   // Right
+		nil,                  // Bottom
+		nil,                  // Left
+		nil,                  // Right
 		container.NewVSplit(
 			a.filterView.Render(), // Top section - filters
 			a.listView.Render(),   // Bottom section - list
 		),
 	)
-
-	a.window.SetContent(content)
-	a.window.Resize(fyne.NewSize(1000, 700))
-	a.window.ShowAndRun()
 }
 
 func (a *App) Refresh() {
@@ -81,6 +123,58 @@ func (a *App) Refresh() {
 	a.listView.Refresh()
 }
 
+// reload shows the data again after another program changed it
+func (a *App) reload() {
+	if err := a.service.AcceptStorageChanges(); err != nil {
+		log.Printf("Warning: Failed to reload the data file: %v", err)
+	}
+	a.filterView.RefreshCategories()
+	a.filterView.RefreshPaymentMethods()
+	a.Refresh()
+}
+
+// applyScheduledChanges catches up on everything that happens by date since the
+// data was last saved: trial conversions, scheduled prices, auto-resumes and
+// the payments generated from them
+func (a *App) applyScheduledChanges() error {
+	if err := a.service.ConvertEndedTrials(); err != nil {
+		return fmt.Errorf("failed to convert ended trials: %w", err)
+	}
+
+	if err := a.service.ApplyScheduledPrices(); err != nil {
+		return fmt.Errorf("failed to apply scheduled price changes: %w", err)
+	}
+
+	if err := a.service.ResumeDuePauses(); err != nil {
+		return fmt.Errorf("failed to resume paused subscriptions: %w", err)
+	}
+
+	if err := a.paymentService.GenerateAllPayments(); err != nil {
+		return fmt.Errorf("failed to generate payment history: %w", err)
+	}
+
+	return nil
+}
+
+// prepareCategories migrates the category registry and creates any missing
+// default category icons
+func (a *App) prepareCategories() error {
+	if err := a.categoryService.Migrate(); err != nil {
+		return fmt.Errorf("failed to migrate categories: %w", err)
+	}
+
+	categories, err := a.categoryService.List()
+	if err != nil {
+		return fmt.Errorf("failed to load categories: %w", err)
+	}
+
+	if err := images.EnsureDefaultCategoryIcons(categories); err != nil {
+		return fmt.Errorf("failed to create default category icons: %w", err)
+	}
+
+	return nil
+}
+
 func (a *App) setupMenu() {
 	// Create Settings menu
 	settingsItem := fyne.NewMenuItem("Settings", func() {
@@ -88,10 +182,33 @@ func (a *App) setupMenu() {
 		settings.Show()
 	})
 
-	settingsMenu := fyne.NewMenu("Settings", settingsItem)
+	backupsItem := fyne.NewMenuItem("Restore from Backup...", func() {
+		backups := NewBackupsView(a)
+		backups.Show()
+	})
+
+	settingsMenu := fyne.NewMenu("Settings", settingsItem, backupsItem)
+
+	// Create View menu
+	trashItem := fyne.NewMenuItem("Trash", func() {
+		trash := NewTrashView(a)
+		trash.Show()
+	})
+
+	sharedItem := fyne.NewMenuItem("Shared Costs", func() {
+		report := NewSharedReportView(a)
+		report.Show()
+	})
+
+	forecastItem := fyne.NewMenuItem("Forecast", func() {
+		forecast := NewForecastView(a)
+		forecast.Show()
+	})
+
+	viewMenu := fyne.NewMenu("View", forecastItem, sharedItem, trashItem)
 
 	// Set the main menu
-	mainMenu := fyne.NewMainMenu(settingsMenu)
+	mainMenu := fyne.NewMainMenu(settingsMenu, viewMenu)
 	a.window.SetMainMenu(mainMenu)
 }
 
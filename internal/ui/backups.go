@@ -0,0 +1,108 @@
+package ui
+
+import (
+	"fmt"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"subman/internal/storage"
+)
+
+// BackupsView lists the automatic snapshots of the data file and restores one
+type BackupsView struct {
+	app           *App
+	dialog        dialog.Dialog
+	listContainer *fyne.Container
+}
+
+func NewBackupsView(app *App) *BackupsView {
+	return &BackupsView{
+		app: app,
+	}
+}
+
+func (b *BackupsView) Show() {
+	b.listContainer = container.NewVBox()
+	if !b.refresh() {
+		return
+	}
+
+	scroll := container.NewVScroll(b.listContainer)
+	scroll.SetMinSize(fyne.NewSize(520, 360))
+
+	b.dialog = dialog.NewCustom("Restore from Backup", "Close", scroll, b.app.window)
+	b.dialog.Show()
+}
+
+func (b *BackupsView) refresh() bool {
+	backups, err := b.app.service.ListBackups()
+	if err != nil {
+		dialog.ShowError(err, b.app.window)
+		return false
+	}
+
+	b.listContainer.Objects = nil
+	if len(backups) == 0 {
+		b.listContainer.Add(widget.NewLabel("No backups yet. One is taken automatically before your data is saved."))
+	}
+
+	for _, backup := range backups {
+		label := widget.NewLabel(backupSummary(backup))
+		restoreBtn := widget.NewButton("Restore", func() {
+			b.confirmRestore(backup)
+		})
+		if backup.Err != nil {
+			label.Importance = widget.DangerImportance
+			restoreBtn.Disable()
+		}
+
+		b.listContainer.Add(container.NewBorder(nil, nil, nil, restoreBtn, label))
+	}
+	b.listContainer.Refresh()
+	return true
+}
+
+func (b *BackupsView) confirmRestore(backup storage.Backup) {
+	confirm := dialog.NewConfirm(
+		"Restore Backup",
+		fmt.Sprintf("Replace all current data with the backup from %s?\nYour current data is backed up first.",
+			backup.CreatedAt.Format("Jan 2, 2006 15:04")),
+		func(ok bool) {
+			if !ok {
+				return
+			}
+			if err := b.app.service.RestoreBackup(backup.Path); err != nil {
+				dialog.ShowError(err, b.app.window)
+				return
+			}
+
+			// Bring the restored data up to date, as after an import
+			if err := b.app.prepareCategories(); err != nil {
+				dialog.ShowError(err, b.app.window)
+			}
+			if err := b.app.applyScheduledChanges(); err != nil {
+				dialog.ShowError(err, b.app.window)
+			}
+			b.app.filterView.RefreshCategories()
+			b.app.filterView.RefreshPaymentMethods()
+			b.app.Refresh()
+
+			b.dialog.Hide()
+			dialog.ShowInformation("Restore Complete", backupSummary(backup)+" restored.", b.app.window)
+		},
+		b.app.window,
+	)
+	confirm.Show()
+}
+
+// backupSummary describes a snapshot, e.g. "Oct 17, 2026 15:30 - 12 subscriptions, 340 payments"
+func backupSummary(backup storage.Backup) string {
+	when := backup.CreatedAt.Format("Jan 2, 2006 15:04")
+	if backup.Err != nil {
+		return fmt.Sprintf("%s - unreadable: %v", when, backup.Err)
+	}
+	return fmt.Sprintf("%s - %d subscriptions, %d payments", when, backup.Subscriptions, backup.Payments)
+}
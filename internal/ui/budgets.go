@@ -0,0 +1,244 @@
+package ui
+
+import (
+	"fmt"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"subman/internal/models"
+	"subman/pkg/calculator"
+)
+
+var budgetScopeLabels = map[models.BudgetScope]string{
+	models.BudgetOverall:  "Overall",
+	models.BudgetCategory: "Category",
+	models.BudgetTag:      "Tag",
+}
+
+var budgetScopes = []models.BudgetScope{models.BudgetOverall, models.BudgetCategory, models.BudgetTag}
+
+// BudgetsView lets the user maintain monthly budgets overall, per category and per tag
+type BudgetsView struct {
+	app           *App
+	listContainer *fyne.Container
+}
+
+func NewBudgetsView(app *App) *BudgetsView {
+	return &BudgetsView{
+		app: app,
+	}
+}
+
+func (b *BudgetsView) Show() {
+	b.listContainer = container.NewVBox()
+	b.refresh()
+
+	addBtn := widget.NewButton("Add Budget", func() {
+		b.showEditDialog(nil)
+	})
+
+	scroll := container.NewVScroll(b.listContainer)
+	scroll.SetMinSize(fyne.NewSize(500, 300))
+
+	d := dialog.NewCustom("Budgets", "Close", container.NewBorder(nil, addBtn, nil, nil, scroll), b.app.window)
+	d.Show()
+}
+
+func (b *BudgetsView) refresh() {
+	statuses, err := b.app.budgetService.Status()
+	if err != nil {
+		dialog.ShowError(err, b.app.window)
+		return
+	}
+	categories, _ := b.app.categoryService.List()
+
+	b.listContainer.Objects = nil
+	if len(statuses) == 0 {
+		b.listContainer.Add(widget.NewLabel("No budgets yet."))
+	}
+
+	for _, status := range statuses {
+		budget := status.Budget
+		label := widget.NewLabel(fmt.Sprintf("%s - %s/mo", budgetLabel(categories, budget), budget.Limit))
+		label.Importance = budgetImportance(status)
+
+		editBtn := widget.NewButton("Edit", func() {
+			b.showEditDialog(&budget)
+		})
+		deleteBtn := widget.NewButton("Delete", func() {
+			b.confirmDelete(budget)
+		})
+
+		b.listContainer.Add(container.NewBorder(nil, nil, nil, container.NewHBox(editBtn, deleteBtn), label))
+	}
+	b.listContainer.Refresh()
+}
+
+// showEditDialog edits an existing budget, or creates one when budget is nil
+func (b *BudgetsView) showEditDialog(budget *models.Budget) {
+	editing := models.Budget{Scope: models.BudgetOverall, Limit: models.Zero(b.baseCurrency())}
+	if budget != nil {
+		editing = *budget
+	}
+
+	categories, _ := b.app.categoryService.List()
+
+	categorySelect := widget.NewSelect(categoryNames(categories), nil)
+	if editing.Scope == models.BudgetCategory {
+		categorySelect.Selected = categoryName(categories, models.Category(editing.Target))
+	}
+
+	tagEntry := widget.NewEntry()
+	tagEntry.SetPlaceHolder("e.g. work")
+	if editing.Scope == models.BudgetTag {
+		tagEntry.SetText(editing.Target)
+	}
+
+	// Only the field matching the budget's scope applies
+	updateTarget := func(scope models.BudgetScope) {
+		categorySelect.Disable()
+		tagEntry.Disable()
+		switch scope {
+		case models.BudgetCategory:
+			categorySelect.Enable()
+		case models.BudgetTag:
+			tagEntry.Enable()
+		}
+	}
+
+	scopeOptions := make([]string, len(budgetScopes))
+	for i, scope := range budgetScopes {
+		scopeOptions[i] = budgetScopeLabels[scope]
+	}
+	scopeSelect := widget.NewSelect(scopeOptions, nil)
+	scopeSelect.Selected = budgetScopeLabels[editing.Scope]
+
+	limitEntry := widget.NewEntry()
+	limitEntry.SetPlaceHolder("Per month")
+	if !editing.Limit.IsZero() {
+		limitEntry.SetText(editing.Limit.Decimal())
+	}
+	currencySelect := widget.NewSelect(models.Currencies, nil)
+	currencySelect.Selected = editing.Limit.CurrencyCode()
+
+	form := widget.NewForm(
+		widget.NewFormItem("Budget", scopeSelect),
+		widget.NewFormItem("Category", categorySelect),
+		widget.NewFormItem("Tag", tagEntry),
+		widget.NewFormItem("Monthly Limit", container.NewBorder(nil, nil, nil, currencySelect, limitEntry)),
+	)
+	scopeSelect.OnChanged = func(value string) {
+		updateTarget(budgetScopeForLabel(value))
+	}
+	updateTarget(editing.Scope)
+
+	title := "Add Budget"
+	if budget != nil {
+		title = "Edit Budget"
+	}
+
+	d := dialog.NewCustomConfirm(title, "Save", "Cancel", form, func(ok bool) {
+		if !ok {
+			return
+		}
+
+		limit, err := models.ParseMoney(limitEntry.Text, currencySelect.Selected)
+		if err != nil {
+			dialog.ShowError(err, b.app.window)
+			return
+		}
+
+		editing.Scope = budgetScopeForLabel(scopeSelect.Selected)
+		editing.Limit = limit
+		switch editing.Scope {
+		case models.BudgetCategory:
+			editing.Target = string(categoryIDForName(categories, categorySelect.Selected))
+		case models.BudgetTag:
+			editing.Target = tagEntry.Text
+		default:
+			editing.Target = ""
+		}
+
+		if budget == nil {
+			err = b.app.budgetService.Create(&editing)
+		} else {
+			err = b.app.budgetService.Update(&editing)
+		}
+		if err != nil {
+			dialog.ShowError(err, b.app.window)
+			return
+		}
+
+		b.app.Refresh()
+		b.refresh()
+	}, b.app.window)
+	d.Resize(fyne.NewSize(400, 0))
+	d.Show()
+}
+
+func (b *BudgetsView) confirmDelete(budget models.Budget) {
+	categories, _ := b.app.categoryService.List()
+	confirm := dialog.NewConfirm(
+		"Delete Budget",
+		fmt.Sprintf("Delete the %s budget?", budgetLabel(categories, budget)),
+		func(ok bool) {
+			if !ok {
+				return
+			}
+			if err := b.app.budgetService.Delete(budget.ID); err != nil {
+				dialog.ShowError(err, b.app.window)
+				return
+			}
+			b.app.Refresh()
+			b.refresh()
+		},
+		b.app.window,
+	)
+	confirm.Show()
+}
+
+// baseCurrency returns the currency new budgets default to
+func (b *BudgetsView) baseCurrency() string {
+	rates, err := b.app.service.GetExchangeRates()
+	if err != nil {
+		return models.DefaultCurrency
+	}
+	return rates.BaseCurrency()
+}
+
+// budgetScopeForLabel maps a scope select option back to its scope
+func budgetScopeForLabel(label string) models.BudgetScope {
+	for scope, scopeLabel := range budgetScopeLabels {
+		if scopeLabel == label {
+			return scope
+		}
+	}
+	return models.BudgetOverall
+}
+
+// budgetLabel names what a budget limits, e.g. "Overall", "Streaming" or "Tag: work"
+func budgetLabel(categories []models.CategoryDef, budget models.Budget) string {
+	switch budget.Scope {
+	case models.BudgetCategory:
+		return categoryName(categories, models.Category(budget.Target))
+	case models.BudgetTag:
+		return "Tag: " + budget.Target
+	default:
+		return "Overall"
+	}
+}
+
+// budgetImportance colours a budget red when over its limit and orange when close
+func budgetImportance(status calculator.BudgetStatus) widget.Importance {
+	switch {
+	case status.Over():
+		return widget.DangerImportance
+	case status.Near():
+		return widget.WarningImportance
+	default:
+		return widget.MediumImportance
+	}
+}
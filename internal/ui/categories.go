@@ -0,0 +1,280 @@
+package ui
+
+import (
+	"fmt"
+	"image/color"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/storage"
+	"fyne.io/fyne/v2/widget"
+
+	"subman/internal/images"
+	"subman/internal/models"
+)
+
+// CategoriesView lets the user add, edit and remove categories
+type CategoriesView struct {
+	app           *App
+	listContainer *fyne.Container
+}
+
+func NewCategoriesView(app *App) *CategoriesView {
+	return &CategoriesView{
+		app: app,
+	}
+}
+
+func (c *CategoriesView) Show() {
+	c.listContainer = container.NewVBox()
+	c.refresh()
+
+	addBtn := widget.NewButton("Add Category", func() {
+		c.showEditDialog(nil)
+	})
+
+	scroll := container.NewVScroll(c.listContainer)
+	scroll.SetMinSize(fyne.NewSize(420, 360))
+
+	d := dialog.NewCustom("Categories", "Close", container.NewBorder(nil, addBtn, nil, nil, scroll), c.app.window)
+	d.Show()
+}
+
+func (c *CategoriesView) refresh() {
+	categories, err := c.app.categoryService.List()
+	if err != nil {
+		dialog.ShowError(err, c.app.window)
+		return
+	}
+
+	c.listContainer.Objects = nil
+	for _, def := range categories {
+		def := def
+		swatchColor, _ := images.ParseHexColor(def.Color)
+		swatch := canvas.NewRectangle(swatchColor)
+		swatch.SetMinSize(fyne.NewSize(20, 20))
+
+		editBtn := widget.NewButton("Edit", func() {
+			c.showEditDialog(&def)
+		})
+		deleteBtn := widget.NewButton("Delete", func() {
+			c.confirmDelete(def, categories)
+		})
+
+		c.listContainer.Add(container.NewBorder(nil, nil,
+			container.NewCenter(swatch),
+			container.NewHBox(editBtn, deleteBtn),
+			widget.NewLabel(def.Name),
+		))
+	}
+	c.listContainer.Refresh()
+}
+
+// showEditDialog edits an existing category, or creates one when def is nil
+func (c *CategoriesView) showEditDialog(def *models.CategoryDef) {
+	editing := models.CategoryDef{Color: models.DefaultCategoryColor}
+	if def != nil {
+		editing = *def
+	}
+
+	nameEntry := widget.NewEntry()
+	nameEntry.SetText(editing.Name)
+
+	colorEntry := widget.NewEntry()
+	colorEntry.SetPlaceHolder("#rrggbb")
+	colorEntry.SetText(editing.Color)
+	pickBtn := widget.NewButton("Pick...", func() {
+		picker := dialog.NewColorPicker("Category Colour", "", func(picked color.Color) {
+			colorEntry.SetText(hexColor(picked))
+		}, c.app.window)
+		picker.Advanced = true
+		picker.Show()
+	})
+
+	// Icon: generated from the colour unless the user chooses an image
+	iconPath := ""
+	useColor := !editing.CustomIcon
+	iconLabel := widget.NewLabel("Generated from colour")
+	if editing.CustomIcon {
+		iconLabel.SetText("Custom image")
+	}
+	chooseBtn := widget.NewButton("Choose Image", func() {
+		fd := dialog.NewFileOpen(func(reader fyne.URIReadCloser, err error) {
+			if err != nil || reader == nil {
+				return
+			}
+			defer reader.Close()
+			iconPath = reader.URI().Path()
+			useColor = false
+			iconLabel.SetText("Selected: " + reader.URI().Name())
+		}, c.app.window)
+		fd.SetFilter(storage.NewExtensionFileFilter([]string{".png", ".jpg", ".jpeg", ".gif"}))
+		fd.Show()
+	})
+	resetBtn := widget.NewButton("Use Colour", func() {
+		iconPath = ""
+		useColor = true
+		iconLabel.SetText("Generated from colour")
+	})
+
+	form := widget.NewForm(
+		widget.NewFormItem("Name", nameEntry),
+		widget.NewFormItem("Colour", container.NewBorder(nil, nil, nil, pickBtn, colorEntry)),
+		widget.NewFormItem("Icon", container.NewVBox(iconLabel, container.NewHBox(chooseBtn, resetBtn))),
+	)
+
+	title := "Add Category"
+	if def != nil {
+		title = "Edit Category"
+	}
+
+	d := dialog.NewCustomConfirm(title, "Save", "Cancel", form, func(ok bool) {
+		if !ok {
+			return
+		}
+
+		if _, err := images.ParseHexColor(colorEntry.Text); err != nil {
+			dialog.ShowError(err, c.app.window)
+			return
+		}
+		editing.Name = nameEntry.Text
+		editing.Color = colorEntry.Text
+
+		var err error
+		if def == nil {
+			err = c.app.categoryService.Create(&editing)
+		}
+		if err == nil {
+			err = c.saveIcon(&editing, iconPath, useColor)
+		}
+		if err != nil {
+			dialog.ShowError(err, c.app.window)
+			return
+		}
+
+		c.app.filterView.RefreshCategories()
+		c.app.Refresh()
+		c.refresh()
+	}, c.app.window)
+	d.Resize(fyne.NewSize(420, 0))
+	d.Show()
+}
+
+// saveIcon writes the category's icon (a chosen image, or a square in its
+// colour) and stores the category
+func (c *CategoriesView) saveIcon(def *models.CategoryDef, iconPath string, useColor bool) error {
+	switch {
+	case iconPath != "":
+		if err := images.SetCategoryIcon(*def, iconPath); err != nil {
+			return err
+		}
+		def.CustomIcon = true
+	case useColor:
+		if err := images.WriteCategoryIcon(*def); err != nil {
+			return err
+		}
+		def.CustomIcon = false
+	}
+
+	return c.app.categoryService.Update(def)
+}
+
+// confirmDelete removes a category after asking where its subscriptions should go
+func (c *CategoriesView) confirmDelete(def models.CategoryDef, categories []models.CategoryDef) {
+	var others []models.CategoryDef
+	for _, other := range categories {
+		if other.ID != def.ID {
+			others = append(others, other)
+		}
+	}
+	if len(others) == 0 {
+		dialog.ShowInformation("Delete Category", "The last category cannot be deleted.", c.app.window)
+		return
+	}
+
+	moveSelect := widget.NewSelect(categoryNames(others), nil)
+	moveSelect.Selected = others[0].Name
+	if _, ok := lookupCategory(others, models.Other); ok {
+		moveSelect.Selected = categoryName(others, models.Other)
+	}
+
+	content := container.NewVBox(
+		widget.NewLabel(fmt.Sprintf("Delete the '%s' category?", def.Name)),
+		widget.NewLabel("Move its subscriptions to:"),
+		moveSelect,
+	)
+
+	confirm := dialog.NewCustomConfirm("Delete Category", "Delete", "Cancel", content, func(ok bool) {
+		if !ok {
+			return
+		}
+		if err := c.app.categoryService.Delete(def.ID, categoryIDForName(others, moveSelect.Selected)); err != nil {
+			dialog.ShowError(err, c.app.window)
+			return
+		}
+		c.app.filterView.RefreshCategories()
+		c.app.Refresh()
+		c.refresh()
+	}, c.app.window)
+	confirm.Show()
+}
+
+// hexColor formats a colour as "#rrggbb"
+func hexColor(c color.Color) string {
+	r, g, b, _ := c.RGBA()
+	return fmt.Sprintf("#%02x%02x%02x", r>>8, g>>8, b>>8)
+}
+
+// categoryNames returns the display names of the categories, in registry order
+func categoryNames(categories []models.CategoryDef) []string {
+	names := make([]string, len(categories))
+	for i, def := range categories {
+		names[i] = def.Name
+	}
+	return names
+}
+
+// lookupCategory finds a category by ID
+func lookupCategory(categories []models.CategoryDef, id models.Category) (models.CategoryDef, bool) {
+	for _, def := range categories {
+		if def.ID == id {
+			return def, true
+		}
+	}
+	return models.CategoryDef{}, false
+}
+
+// findCategory returns the category for an ID, or a plain entry named after
+// the ID when it isn't registered
+func findCategory(categories []models.CategoryDef, id models.Category) models.CategoryDef {
+	if def, ok := lookupCategory(categories, id); ok {
+		return def
+	}
+	return models.CategoryDef{ID: id, Name: string(id), Color: models.DefaultCategoryColor}
+}
+
+// categoryName returns the display name for a category ID
+func categoryName(categories []models.CategoryDef, id models.Category) string {
+	return findCategory(categories, id).Name
+}
+
+// categoryIDForName maps a display name chosen in a select back to its ID
+func categoryIDForName(categories []models.CategoryDef, name string) models.Category {
+	for _, def := range categories {
+		if def.Name == name {
+			return def.ID
+		}
+	}
+	return models.Other
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
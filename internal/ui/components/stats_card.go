@@ -7,7 +7,11 @@ import (
 )
 
 func NewStatsCard(title string, valueLabel *widget.Label) fyne.CanvasObject {
-	titleLabel := widget.NewLabel(title)
+	return NewStatsCardWithTitle(widget.NewLabel(title), valueLabel)
+}
+
+// NewStatsCardWithTitle builds a stats card whose title can change after rendering
+func NewStatsCardWithTitle(titleLabel *widget.Label, valueLabel *widget.Label) fyne.CanvasObject {
 	titleLabel.TextStyle = fyne.TextStyle{Bold: true}
 
 	valueLabel.TextStyle = fyne.TextStyle{Bold: true}
@@ -3,6 +3,8 @@ package components
 import (
 	"fmt"
 	"image/color"
+	"strings"
+	"time"
 
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/canvas"
@@ -13,7 +15,7 @@ import (
 	"subman/internal/models"
 )
 
-func NewSubscriptionCard(sub models.Subscription, onEdit func(models.Subscription), onDelete func(models.Subscription), bgColor color.Color) fyne.CanvasObject {
+func NewSubscriptionCard(sub models.Subscription, category models.CategoryDef, method *models.PaymentMethod, onEdit func(models.Subscription), onDelete func(models.Subscription), onHistory func(models.Subscription), bgColor color.Color) fyne.CanvasObject {
 	// Load image
 	var imageWidget *canvas.Image
 	imagePath, err := images.GetImagePath(sub.Image)
@@ -35,18 +37,58 @@ func NewSubscriptionCard(sub models.Subscription, onEdit func(models.Subscriptio
 
 	// Show paused status
 	var costText string
-	if sub.Paused {
-		costText = fmt.Sprintf("$%.2f / %s (PAUSED)", sub.Cost, sub.BillingCycle)
+	if sub.IsCancelled() {
+		costText = fmt.Sprintf("%s / %s (CANCELLED)", sub.PriceAt(sub.CancelledAt), sub.CycleLabel())
+	} else if sub.Paused {
+		costText = fmt.Sprintf("%s / %s (PAUSED)", sub.BilledCost(), sub.CycleLabel())
+	} else if sub.InTrial(time.Now()) {
+		costText = fmt.Sprintf("Free trial, then %s / %s", sub.BilledCost(), sub.CycleLabel())
 	} else {
-		costText = fmt.Sprintf("$%.2f / %s", sub.Cost, sub.BillingCycle)
+		costText = fmt.Sprintf("%s / %s", sub.Cost, sub.CycleLabel())
 	}
 	costLabel := widget.NewLabel(costText)
 
-	categoryLabel := widget.NewLabel(string(sub.Category))
+	// Category name with its colour
+	swatchColor, _ := images.ParseHexColor(category.Color)
+	swatch := canvas.NewRectangle(swatchColor)
+	swatch.SetMinSize(fyne.NewSize(12, 12))
+	swatch.CornerRadius = 6
+	categoryLabel := container.NewHBox(container.NewCenter(swatch), widget.NewLabel(category.Name))
+
+	info := container.NewVBox(
+		nameLabel,
+		costLabel,
+	)
+
+	// Price trend since the subscription started
+	if percent, ok := sub.PriceChangePercent(time.Now()); ok {
+		direction := "increased"
+		if percent < 0 {
+			direction = "decreased"
+			percent = -percent
+		}
+		info.Add(widget.NewLabel(fmt.Sprintf("Price %s %.0f%% since you subscribed", direction, percent)))
+	}
+
+	// Flag scheduled price increases (e.g. an intro offer ending)
+	if change, ok := sub.NextPriceChange(time.Now()); ok && change.Cost.Amount > sub.BilledCost().Amount {
+		increaseLabel := widget.NewLabel(fmt.Sprintf("Price rises to %s on %s", change.Cost, change.EffectiveFrom.Format("Jan 2, 2006")))
+		increaseLabel.Importance = widget.WarningImportance
+		info.Add(increaseLabel)
+	}
 
 	var nextPaymentText string
-	if sub.Paused {
+	if sub.IsCancelled() {
+		nextPaymentText = fmt.Sprintf("Cancelled on %s", sub.CancelledAt.Format("Jan 2, 2006"))
+		if sub.HasAccess(time.Now()) {
+			nextPaymentText += fmt.Sprintf(" - access until %s", sub.AccessEndsAt.Format("Jan 2, 2006"))
+		}
+	} else if resume := sub.PausedUntil(); sub.Paused && !resume.IsZero() {
+		nextPaymentText = fmt.Sprintf("Paused until %s", resume.Format("Jan 2"))
+	} else if sub.Paused {
 		nextPaymentText = "Subscription is paused"
+	} else if now := time.Now(); sub.InTrial(now) {
+		nextPaymentText = fmt.Sprintf("Trial ends in %d days", sub.TrialDaysLeft(now))
 	} else {
 		nextPaymentText = fmt.Sprintf("Next: %s", sub.NextPayment.Format("Jan 2, 2006"))
 	}
@@ -60,14 +102,37 @@ func NewSubscriptionCard(sub models.Subscription, onEdit func(models.Subscriptio
 		onDelete(sub)
 	})
 
-	info := container.NewVBox(
-		nameLabel,
-		costLabel,
-		categoryLabel,
-		nextPaymentLabel,
-	)
+	historyBtn := widget.NewButton("History", func() {
+		onHistory(sub)
+	})
+
+	// Who the cost is split with and what I pay
+	if sub.IsShared() {
+		names := make([]string, len(sub.Sharing.Participants))
+		for i, p := range sub.Sharing.Participants {
+			names[i] = p.Name
+		}
+		sharedText := fmt.Sprintf("Shared with %s - your share %s", strings.Join(names, ", "), sub.MyShare(sub.BilledCost()))
+		if sub.Payer() != models.Me {
+			sharedText += fmt.Sprintf(", paid by %s", sub.Payer())
+		}
+		info.Add(widget.NewLabel(sharedText))
+	}
+
+	info.Add(categoryLabel)
+	if method != nil {
+		methodLabel := widget.NewLabel("Billed to " + method.String())
+		if method.ExpiresWithin(time.Now(), 0) {
+			methodLabel.Importance = widget.DangerImportance
+		}
+		info.Add(methodLabel)
+	}
+	if len(sub.Tags) > 0 {
+		info.Add(NewTagChips(sub.Tags))
+	}
+	info.Add(nextPaymentLabel)
 
-	actions := container.NewHBox(editBtn, deleteBtn)
+	actions := container.NewHBox(historyBtn, editBtn, deleteBtn)
 
 	// Create card with image on the left
 	card := container.NewBorder(
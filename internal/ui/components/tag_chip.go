@@ -0,0 +1,29 @@
+package components
+
+import (
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+// NewTagChip renders a tag as a small rounded label
+func NewTagChip(tag string) fyne.CanvasObject {
+	background := canvas.NewRectangle(theme.Color(theme.ColorNameInputBackground))
+	background.CornerRadius = 8
+
+	label := widget.NewLabel(tag)
+	label.SizeName = theme.SizeNameCaptionText
+
+	return container.NewStack(background, label)
+}
+
+// NewTagChips renders a row of tag chips
+func NewTagChips(tags []string) fyne.CanvasObject {
+	row := container.NewHBox()
+	for _, tag := range tags {
+		row.Add(NewTagChip(tag))
+	}
+	return row
+}
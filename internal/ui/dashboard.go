@@ -2,29 +2,52 @@ package ui
 
 import (
 	"fmt"
+	"sort"
+	"strings"
+	"time"
 
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/container"
 	"fyne.io/fyne/v2/widget"
 
+	"subman/internal/models"
 	"subman/internal/ui/components"
+	"subman/pkg/calculator"
 )
 
 type DashboardView struct {
-	app        *App
+	app          *App
+	ytdTitle     *widget.Label
 	monthlyLabel *widget.Label
 	yearlyLabel  *widget.Label
 	ytdLabel     *widget.Label
 	countLabel   *widget.Label
+	trialLabel   *widget.Label
+	savedLabel   *widget.Label
+	shareLabel   *widget.Label
+	budgetLabel  *widget.Label
+	budgetsBox   *fyne.Container
+	tagsLabel    *widget.Label
+	warningLabel *widget.Label
+	spending     *SpendingPeriodView
 }
 
 func NewDashboardView(app *App) *DashboardView {
 	return &DashboardView{
 		app:          app,
+		ytdTitle:     widget.NewLabel("Year to Date"),
 		monthlyLabel: widget.NewLabel("$0.00"),
 		yearlyLabel:  widget.NewLabel("$0.00"),
 		ytdLabel:     widget.NewLabel("$0.00"),
 		countLabel:   widget.NewLabel("0"),
+		trialLabel:   widget.NewLabel("0"),
+		savedLabel:   widget.NewLabel("$0.00"),
+		shareLabel:   widget.NewLabel("$0.00"),
+		budgetLabel:  widget.NewLabel("None set"),
+		budgetsBox:   container.NewVBox(),
+		tagsLabel:    widget.NewLabel(""),
+		warningLabel: widget.NewLabel(""),
+		spending:     NewSpendingPeriodView(app),
 	}
 }
 
@@ -33,14 +56,33 @@ func (d *DashboardView) Render() fyne.CanvasObject {
 
 	monthlyCard := components.NewStatsCard("Monthly Total", d.monthlyLabel)
 	yearlyCard := components.NewStatsCard("Yearly Total", d.yearlyLabel)
-	ytdCard := components.NewStatsCard("Year to Date", d.ytdLabel)
+	ytdCard := components.NewStatsCardWithTitle(d.ytdTitle, d.ytdLabel)
 	countCard := components.NewStatsCard("Active Subscriptions", d.countLabel)
+	trialCard := components.NewStatsCard("Free Trials", d.trialLabel)
+	savedCard := components.NewStatsCard("Saved by Cancelling", d.savedLabel)
+	shareCard := components.NewStatsCard("My Share", d.shareLabel)
+	budgetCard := components.NewStatsCard("Budget", d.budgetLabel)
 
-	return container.NewHBox(
-		monthlyCard,
-		yearlyCard,
-		ytdCard,
-		countCard,
+	d.tagsLabel.Wrapping = fyne.TextWrapWord
+
+	d.warningLabel.Importance = widget.WarningImportance
+	d.warningLabel.Wrapping = fyne.TextWrapWord
+
+	return container.NewVBox(
+		container.NewHBox(
+			monthlyCard,
+			yearlyCard,
+			ytdCard,
+			countCard,
+			trialCard,
+			savedCard,
+			shareCard,
+			budgetCard,
+		),
+		d.budgetsBox,
+		d.tagsLabel,
+		d.spending.Render(),
+		d.warningLabel,
 	)
 }
 
@@ -51,11 +93,140 @@ func (d *DashboardView) Refresh() {
 		d.yearlyLabel.SetText("Error")
 		d.ytdLabel.SetText("Error")
 		d.countLabel.SetText("Error")
+		d.trialLabel.SetText("Error")
+		d.savedLabel.SetText("Error")
+		d.shareLabel.SetText("Error")
+		d.budgetLabel.SetText("Error")
+		d.tagsLabel.Hide()
+		d.warningLabel.Hide()
 		return
 	}
 
-	d.monthlyLabel.SetText(fmt.Sprintf("$%.2f", summary.TotalMonthly))
-	d.yearlyLabel.SetText(fmt.Sprintf("$%.2f", summary.TotalYearly))
-	d.ytdLabel.SetText(fmt.Sprintf("$%.2f", summary.YearToDate))
+	d.monthlyLabel.SetText(summary.TotalMonthly.String())
+	d.yearlyLabel.SetText(summary.TotalYearly.String())
+	d.ytdLabel.SetText(summary.YearToDate.String())
+	d.ytdTitle.SetText(yearToDateTitle(summary.YearStart))
 	d.countLabel.SetText(fmt.Sprintf("%d", summary.Count))
+	if summary.TrialsEnding > 0 {
+		d.trialLabel.SetText(fmt.Sprintf("%d (%d converting this week)", summary.TrialCount, summary.TrialsEnding))
+	} else {
+		d.trialLabel.SetText(fmt.Sprintf("%d", summary.TrialCount))
+	}
+
+	d.savedLabel.SetText(fmt.Sprintf("%s/mo (%s so far)", summary.SavedMonthly, summary.SavedTotal))
+
+	if summary.SharedCount > 0 {
+		d.shareLabel.SetText(fmt.Sprintf("%s/mo (%d shared)", summary.MyMonthly, summary.SharedCount))
+	} else {
+		d.shareLabel.SetText(fmt.Sprintf("%s/mo", summary.MyMonthly))
+	}
+
+	d.refreshBudgets()
+	d.refreshTagTotals(summary)
+	d.refreshWarnings(summary)
+	if d.spending.periodSelect != nil {
+		d.spending.Refresh()
+	}
+}
+
+// yearToDateTitle names the YTD card after the fiscal year, e.g. "FY 2026/27 to Date"
+func yearToDateTitle(yearStart time.Time) string {
+	if yearStart.Month() == time.January {
+		return "Year to Date"
+	}
+	return calculator.FiscalYearLabel(yearStart) + " to Date"
+}
+
+// refreshBudgets shows how much of each budget is used; the overall budget in
+// its card and category or tag budgets below the cards, red when over the limit
+func (d *DashboardView) refreshBudgets() {
+	d.budgetsBox.Objects = nil
+	d.monthlyLabel.Importance = widget.MediumImportance
+	d.budgetLabel.Importance = widget.MediumImportance
+	d.budgetLabel.SetText("None set")
+
+	statuses, err := d.app.budgetService.Status()
+	if err != nil {
+		d.budgetLabel.SetText("Error")
+		d.budgetsBox.Refresh()
+		return
+	}
+	categories, _ := d.app.categoryService.List()
+
+	for _, status := range statuses {
+		importance := budgetImportance(status)
+		text := fmt.Sprintf("%s/mo of %s (%.0f%%), %s paid this month",
+			status.Planned, status.Limit, status.PlannedPercent, status.Spent)
+
+		if status.Budget.Scope == models.BudgetOverall {
+			d.budgetLabel.Importance = importance
+			d.budgetLabel.SetText(fmt.Sprintf("%s of %s (%.0f%%)\n%s paid this month",
+				status.Planned, status.Limit, status.PlannedPercent, status.Spent))
+			if status.Over() {
+				d.monthlyLabel.Importance = widget.DangerImportance
+			}
+			continue
+		}
+
+		label := widget.NewLabel(fmt.Sprintf("Budget %s - %s", budgetLabel(categories, status.Budget), text))
+		label.Importance = importance
+		d.budgetsBox.Add(label)
+	}
+
+	d.monthlyLabel.Refresh()
+	d.budgetLabel.Refresh()
+	d.budgetsBox.Refresh()
+}
+
+// refreshTagTotals lists the monthly cost per tag below the stats cards
+func (d *DashboardView) refreshTagTotals(summary *models.CostSummary) {
+	if len(summary.ByTag) == 0 {
+		d.tagsLabel.Hide()
+		return
+	}
+
+	tags := make([]string, 0, len(summary.ByTag))
+	for tag := range summary.ByTag {
+		tags = append(tags, tag)
+	}
+	sort.Strings(tags)
+
+	totals := make([]string, len(tags))
+	for i, tag := range tags {
+		totals[i] = fmt.Sprintf("%s: %s/mo", tag, summary.ByTag[tag])
+	}
+	d.tagsLabel.SetText("By tag - " + strings.Join(totals, "   "))
+	d.tagsLabel.Show()
+}
+
+// refreshWarnings shows problems with the totals below the stats cards
+func (d *DashboardView) refreshWarnings(summary *models.CostSummary) {
+	var warnings []string
+	if len(summary.MissingRates) > 0 {
+		warnings = append(warnings, fmt.Sprintf("No exchange rate to %s for %s - those amounts are counted 1:1. Add rates in Settings.",
+			summary.Currency, strings.Join(summary.MissingRates, ", ")))
+	}
+
+	expiring, err := d.app.methodService.ExpiringSoon()
+	if err == nil {
+		for _, entry := range expiring {
+			names := make([]string, len(entry.Subscriptions))
+			for i, sub := range entry.Subscriptions {
+				names[i] = sub.Name
+			}
+			status := "expires"
+			if entry.Method.ExpiresWithin(time.Now(), 0) {
+				status = "has expired"
+			}
+			warnings = append(warnings, fmt.Sprintf("%s %s at the end of %s - update %s.",
+				entry.Method.Label, status, entry.Method.ExpiryMonth.Format("January 2006"), strings.Join(names, ", ")))
+		}
+	}
+
+	if len(warnings) == 0 {
+		d.warningLabel.Hide()
+		return
+	}
+	d.warningLabel.SetText(strings.Join(warnings, "\n"))
+	d.warningLabel.Show()
 }
@@ -0,0 +1,165 @@
+package ui
+
+import (
+	"errors"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+)
+
+var errPassphraseMismatch = errors.New("the passphrases don't match")
+
+// UnlockView asks for the passphrase of encrypted data before the app starts
+type UnlockView struct {
+	app *App
+}
+
+func NewUnlockView(app *App) *UnlockView {
+	return &UnlockView{
+		app: app,
+	}
+}
+
+func (u *UnlockView) Render() fyne.CanvasObject {
+	title := widget.NewLabelWithStyle("Your subscription data is encrypted", fyne.TextAlignCenter, fyne.TextStyle{Bold: true})
+	errorLabel := widget.NewLabel("")
+	errorLabel.Importance = widget.DangerImportance
+	errorLabel.Hide()
+
+	passphraseEntry := widget.NewPasswordEntry()
+	passphraseEntry.SetPlaceHolder("Passphrase")
+
+	var unlockBtn *widget.Button
+	unlock := func() {
+		unlockBtn.Disable()
+		defer unlockBtn.Enable()
+
+		if err := u.app.service.Unlock(passphraseEntry.Text); err != nil {
+			errorLabel.SetText(err.Error())
+			errorLabel.Show()
+			passphraseEntry.SetText("")
+			u.app.window.Canvas().Focus(passphraseEntry)
+			return
+		}
+		u.app.unlocked()
+	}
+	unlockBtn = widget.NewButton("Unlock", unlock)
+	unlockBtn.Importance = widget.HighImportance
+	passphraseEntry.OnSubmitted = func(string) { unlock() }
+
+	// Keep the entry wide enough to type into
+	entryBox := container.NewGridWrap(fyne.NewSize(320, passphraseEntry.MinSize().Height), passphraseEntry)
+
+	return container.NewCenter(container.NewVBox(
+		title,
+		widget.NewLabelWithStyle("Enter your passphrase to open it.", fyne.TextAlignCenter, fyne.TextStyle{}),
+		entryBox,
+		unlockBtn,
+		errorLabel,
+	))
+}
+
+// EncryptionView turns encryption on or off and changes the passphrase
+type EncryptionView struct {
+	app *App
+}
+
+func NewEncryptionView(app *App) *EncryptionView {
+	return &EncryptionView{
+		app: app,
+	}
+}
+
+// ShowSetPassphrase encrypts the data, or changes the passphrase when it already is
+func (e *EncryptionView) ShowSetPassphrase(onDone func()) {
+	encrypted, err := e.app.service.IsEncrypted()
+	if err != nil {
+		dialog.ShowError(err, e.app.window)
+		return
+	}
+
+	currentEntry := widget.NewPasswordEntry()
+	newEntry := widget.NewPasswordEntry()
+	confirmEntry := widget.NewPasswordEntry()
+
+	var items []*widget.FormItem
+	title := "Encrypt Data"
+	if encrypted {
+		title = "Change Passphrase"
+		items = append(items, widget.NewFormItem("Current Passphrase", currentEntry))
+	}
+	items = append(items,
+		widget.NewFormItem("New Passphrase", newEntry),
+		widget.NewFormItem("Confirm Passphrase", confirmEntry),
+	)
+
+	info := widget.NewLabel("There is no way to recover your data without the passphrase. Backups are re-encrypted with the new passphrase.")
+	info.Wrapping = fyne.TextWrapWord
+	content := container.NewVBox(info, widget.NewForm(items...))
+
+	d := dialog.NewCustomConfirm(title, "Save", "Cancel", content, func(ok bool) {
+		if !ok {
+			return
+		}
+		if newEntry.Text != confirmEntry.Text {
+			dialog.ShowError(errPassphraseMismatch, e.app.window)
+			return
+		}
+		if err := e.app.service.SetPassphrase(currentEntry.Text, newEntry.Text); err != nil {
+			dialog.ShowError(err, e.app.window)
+			return
+		}
+		onDone()
+	}, e.app.window)
+	d.Resize(fyne.NewSize(420, 300))
+	d.Show()
+}
+
+// ShowTurnOff decrypts the data after checking the current passphrase
+func (e *EncryptionView) ShowTurnOff(onDone func()) {
+	currentEntry := widget.NewPasswordEntry()
+
+	info := widget.NewLabel("Your data and its backups will be stored unencrypted.")
+	info.Wrapping = fyne.TextWrapWord
+	content := container.NewVBox(info, widget.NewForm(widget.NewFormItem("Current Passphrase", currentEntry)))
+
+	d := dialog.NewCustomConfirm("Turn Off Encryption", "Turn Off", "Cancel", content, func(ok bool) {
+		if !ok {
+			return
+		}
+		if err := e.app.service.SetPassphrase(currentEntry.Text, ""); err != nil {
+			dialog.ShowError(err, e.app.window)
+			return
+		}
+		onDone()
+	}, e.app.window)
+	d.Resize(fyne.NewSize(380, 200))
+	d.Show()
+}
+
+// showPassphrasePrompt asks for a passphrase, twice when confirm is set, and
+// passes it to onOK
+func showPassphrasePrompt(title string, confirm bool, window fyne.Window, onOK func(passphrase string)) {
+	passphraseEntry := widget.NewPasswordEntry()
+	confirmEntry := widget.NewPasswordEntry()
+
+	items := []*widget.FormItem{widget.NewFormItem("Passphrase", passphraseEntry)}
+	if confirm {
+		items = append(items, widget.NewFormItem("Confirm Passphrase", confirmEntry))
+	}
+
+	d := dialog.NewForm(title, "OK", "Cancel", items, func(ok bool) {
+		if !ok {
+			return
+		}
+		if confirm && passphraseEntry.Text != confirmEntry.Text {
+			dialog.ShowError(errPassphraseMismatch, window)
+			return
+		}
+		onOK(passphraseEntry.Text)
+	}, window)
+	d.Resize(fyne.NewSize(380, 200))
+	d.Show()
+}
@@ -8,8 +8,11 @@ import (
 	"subman/internal/images"
 	"subman/internal/models"
 	"subman/pkg/export"
+	"subman/pkg/importer"
 )
 
+const encryptedBundleFormat = "Encrypted Bundle (with images)"
+
 type ExportView struct {
 	app           *App
 	subscriptions []models.Subscription
@@ -23,7 +26,7 @@ func NewExportView(app *App, subs []models.Subscription) *ExportView {
 }
 
 func (e *ExportView) Show() {
-	formatSelect := widget.NewRadioGroup([]string{"CSV", "JSON", "Bundle (with images)"}, nil)
+	formatSelect := widget.NewRadioGroup([]string{"CSV", "JSON", "Bundle (with images)", encryptedBundleFormat}, nil)
 	formatSelect.Selected = "CSV"
 
 	content := widget.NewForm(
@@ -31,22 +34,30 @@ func (e *ExportView) Show() {
 	)
 
 	confirm := dialog.NewCustomConfirm("Export Subscriptions", "Export", "Cancel", content, func(ok bool) {
-		if ok {
-			e.doExport(formatSelect.Selected)
+		if !ok {
+			return
+		}
+		if formatSelect.Selected == encryptedBundleFormat {
+			showPassphrasePrompt("Encrypt Bundle", true, e.app.window, func(passphrase string) {
+				e.doExport(formatSelect.Selected, passphrase)
+			})
+			return
 		}
+		e.doExport(formatSelect.Selected, "")
 	}, e.app.window)
 
 	confirm.Show()
 }
 
-func (e *ExportView) doExport(format string) {
+// doExport saves the export; passphrase encrypts an encrypted bundle
+func (e *ExportView) doExport(format, passphrase string) {
 	saveDialog := dialog.NewFileSave(func(writer fyne.URIWriteCloser, err error) {
 		if err != nil || writer == nil {
 			return
 		}
 		defer writer.Close()
 
-		if format == "Bundle (with images)" {
+		if format == "Bundle (with images)" || format == encryptedBundleFormat {
 			// For bundle export, we need the full SubscriptionList with payments
 			list, err := e.app.service.GetStorage().Load()
 			if err != nil {
@@ -61,7 +72,12 @@ func (e *ExportView) doExport(format string) {
 			}
 
 			bundleExporter := export.NewBundleExporter(imagesDir)
-			if err := bundleExporter.ExportBundle(list, writer); err != nil {
+			if format == encryptedBundleFormat {
+				err = bundleExporter.ExportEncryptedBundle(list, writer, passphrase)
+			} else {
+				err = bundleExporter.ExportBundle(list, writer)
+			}
+			if err != nil {
 				dialog.ShowError(err, e.app.window)
 			}
 		} else {
@@ -82,6 +98,8 @@ func (e *ExportView) doExport(format string) {
 		saveDialog.SetFileName("subscriptions.csv")
 	} else if format == "JSON" {
 		saveDialog.SetFileName("subscriptions.json")
+	} else if format == encryptedBundleFormat {
+		saveDialog.SetFileName("subscriptions" + importer.EncryptedBundleExt)
 	} else {
 		saveDialog.SetFileName("subscriptions.zip")
 	}
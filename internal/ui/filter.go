@@ -11,11 +11,17 @@ import (
 )
 
 type FilterView struct {
-	app            *App
-	searchEntry    *widget.Entry
-	categorySelect *widget.Select
-	cycleSelect    *widget.Select
-	showPausedCheck *widget.Check
+	app                *App
+	searchEntry        *widget.Entry
+	categorySelect     *widget.Select
+	categories         []models.CategoryDef
+	methodSelect       *widget.Select
+	methods            []models.PaymentMethod
+	cycleSelect        *widget.Select
+	tagsEntry          *widget.Entry
+	tagMatchRadio      *widget.RadioGroup
+	showPausedCheck    *widget.Check
+	showCancelledCheck *widget.Check
 }
 
 func NewFilterView(app *App) *FilterView {
@@ -31,23 +37,46 @@ func (f *FilterView) Render() fyne.CanvasObject {
 		f.applyFilters()
 	}
 
-	categories := []string{"All", "Streaming", "Software", "Utilities", "Gaming", "News", "Education", "Creator", "Other"}
-	f.categorySelect = widget.NewSelect(categories, func(s string) {
+	f.categorySelect = widget.NewSelect(nil, func(s string) {
 		f.applyFilters()
 	})
 	f.categorySelect.Selected = "All"
+	f.RefreshCategories()
 
-	cycles := []string{"All", "Monthly", "Yearly"}
+	f.methodSelect = widget.NewSelect(nil, func(s string) {
+		f.applyFilters()
+	})
+	f.methodSelect.Selected = "All"
+	f.RefreshPaymentMethods()
+
+	cycles := []string{"All", "Weekly", "Monthly", "Quarterly", "Semiannual", "Yearly", "Custom"}
 	f.cycleSelect = widget.NewSelect(cycles, func(s string) {
 		f.applyFilters()
 	})
 	f.cycleSelect.Selected = "All"
 
+	f.tagsEntry = widget.NewEntry()
+	f.tagsEntry.SetPlaceHolder("Tags, comma separated")
+	f.tagsEntry.OnChanged = func(s string) {
+		f.applyFilters()
+	}
+
+	f.tagMatchRadio = widget.NewRadioGroup([]string{"Any tag", "All tags"}, func(s string) {
+		f.applyFilters()
+	})
+	f.tagMatchRadio.Horizontal = true
+	f.tagMatchRadio.Selected = "Any tag"
+
 	f.showPausedCheck = widget.NewCheck("Show Paused Subscriptions", func(checked bool) {
 		f.applyFilters()
 	})
 	f.showPausedCheck.Checked = false // By default, hide paused subscriptions
 
+	f.showCancelledCheck = widget.NewCheck("Show Cancelled Subscriptions", func(checked bool) {
+		f.applyFilters()
+	})
+	f.showCancelledCheck.Checked = false // By default, hide cancelled subscriptions
+
 	clearBtn := widget.NewButton("Clear Filters", f.clearFilters)
 
 	return container.NewVBox(
@@ -58,8 +87,13 @@ func (f *FilterView) Render() fyne.CanvasObject {
 			f.categorySelect,
 			widget.NewLabel("Billing Cycle:"),
 			f.cycleSelect,
+			widget.NewLabel("Payment Method:"),
+			f.methodSelect,
+			widget.NewLabel("Tags:"),
+			container.NewBorder(nil, nil, nil, f.tagMatchRadio, f.tagsEntry),
 		),
 		f.showPausedCheck,
+		f.showCancelledCheck,
 		clearBtn,
 	)
 }
@@ -67,16 +101,29 @@ func (f *FilterView) Render() fyne.CanvasObject {
 func (f *FilterView) applyFilters() {
 	// Build filter criteria
 	criteria := &models.FilterCriteria{
-		SearchTerm: f.searchEntry.Text,
-		ShowPaused: f.showPausedCheck.Checked,
+		SearchTerm:    f.searchEntry.Text,
+		ShowPaused:    f.showPausedCheck.Checked,
+		ShowCancelled: f.showCancelledCheck.Checked,
+		Tags:          models.ParseTags(f.tagsEntry.Text),
+		TagMatch:      models.MatchAnyTag,
+	}
+
+	if f.tagMatchRadio.Selected == "All tags" {
+		criteria.TagMatch = models.MatchAllTags
 	}
 
 	// Apply category filter
 	if f.categorySelect.Selected != "All" {
-		cat := models.Category(strings.ToLower(f.categorySelect.Selected))
+		cat := categoryIDForName(f.categories, f.categorySelect.Selected)
 		criteria.Category = &cat
 	}
 
+	// Apply payment method filter
+	if f.methodSelect.Selected != "All" {
+		id := paymentMethodIDForLabel(f.methods, f.methodSelect.Selected)
+		criteria.PaymentMethodID = &id
+	}
+
 	// Apply cycle filter
 	if f.cycleSelect.Selected != "All" {
 		cycle := models.BillingCycle(strings.ToLower(f.cycleSelect.Selected))
@@ -87,10 +134,46 @@ func (f *FilterView) applyFilters() {
 	f.app.listView.SetFilter(criteria)
 }
 
+// RefreshCategories reloads the category choices from the registry
+func (f *FilterView) RefreshCategories() {
+	categories, err := f.app.categoryService.List()
+	if err != nil {
+		return
+	}
+	f.categories = categories
+
+	options := append([]string{"All"}, categoryNames(categories)...)
+	f.categorySelect.Options = options
+	if !containsString(options, f.categorySelect.Selected) {
+		f.categorySelect.Selected = "All"
+	}
+	f.categorySelect.Refresh()
+}
+
+// RefreshPaymentMethods reloads the payment method choices
+func (f *FilterView) RefreshPaymentMethods() {
+	methods, err := f.app.methodService.List()
+	if err != nil {
+		return
+	}
+	f.methods = methods
+
+	options := append([]string{"All"}, paymentMethodOptions(methods)...)
+	f.methodSelect.Options = options
+	if !containsString(options, f.methodSelect.Selected) {
+		f.methodSelect.Selected = "All"
+	}
+	f.methodSelect.Refresh()
+}
+
 func (f *FilterView) clearFilters() {
 	f.searchEntry.SetText("")
 	f.categorySelect.Selected = "All"
 	f.cycleSelect.Selected = "All"
+	f.methodSelect.Selected = "All"
+	f.tagsEntry.SetText("")
+	f.tagMatchRadio.Selected = "Any tag"
 	f.showPausedCheck.Checked = false
+	f.showCancelledCheck.Checked = false
 	f.applyFilters()
 }
@@ -0,0 +1,184 @@
+package ui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"subman/internal/models"
+	"subman/pkg/calculator"
+)
+
+var forecastColumns = []string{"Month", "Charges", "Total", "Running Total"}
+
+var forecastHorizons = []string{"3 months", "6 months", "12 months", "24 months"}
+
+// ForecastView shows the upcoming charges month by month
+type ForecastView struct {
+	app           *App
+	forecast      *calculator.Forecast
+	running       []string
+	table         *widget.Table
+	calloutLabel  *widget.Label
+	averageLabel  *widget.Label
+	chargesLabel  *widget.Label
+	horizonSelect *widget.Select
+}
+
+func NewForecastView(app *App) *ForecastView {
+	return &ForecastView{
+		app: app,
+	}
+}
+
+func (f *ForecastView) Show() {
+	f.calloutLabel = widget.NewLabel("")
+	f.calloutLabel.TextStyle = fyne.TextStyle{Bold: true}
+	f.calloutLabel.Wrapping = fyne.TextWrapWord
+	f.averageLabel = widget.NewLabel("")
+	f.averageLabel.Wrapping = fyne.TextWrapWord
+	f.chargesLabel = widget.NewLabel("Select a month to see its charges.")
+	f.chargesLabel.Wrapping = fyne.TextWrapWord
+
+	f.table = widget.NewTableWithHeaders(
+		func() (int, int) {
+			if f.forecast == nil {
+				return 0, len(forecastColumns)
+			}
+			return len(f.forecast.Months), len(forecastColumns)
+		},
+		func() fyne.CanvasObject {
+			return widget.NewLabel("")
+		},
+		func(id widget.TableCellID, cell fyne.CanvasObject) {
+			cell.(*widget.Label).SetText(f.cellText(id))
+		},
+	)
+	f.table.ShowHeaderColumn = false
+	f.table.UpdateHeader = func(id widget.TableCellID, cell fyne.CanvasObject) {
+		if id.Col >= 0 {
+			cell.(*widget.Label).SetText(forecastColumns[id.Col])
+		}
+	}
+	for col, width := range []float32{150, 80, 120, 130} {
+		f.table.SetColumnWidth(col, width)
+	}
+	f.table.OnSelected = func(id widget.TableCellID) {
+		f.showCharges(id.Row)
+	}
+
+	f.horizonSelect = widget.NewSelect(forecastHorizons, func(string) {
+		f.refresh()
+	})
+	f.horizonSelect.Selected = fmt.Sprintf("%d months", calculator.DefaultForecastMonths)
+
+	f.refresh()
+
+	header := container.NewVBox(
+		container.NewHBox(widget.NewLabel("Look ahead:"), f.horizonSelect),
+		f.calloutLabel,
+		f.averageLabel,
+	)
+	chargesScroll := container.NewVScroll(f.chargesLabel)
+	chargesScroll.SetMinSize(fyne.NewSize(0, 120))
+
+	content := container.NewBorder(header, chargesScroll, nil, nil, f.table)
+
+	d := dialog.NewCustom("Forecast", "Close", content, f.app.window)
+	d.Resize(fyne.NewSize(560, 600))
+	d.Show()
+}
+
+func (f *ForecastView) refresh() {
+	months := calculator.DefaultForecastMonths
+	fmt.Sscanf(f.horizonSelect.Selected, "%d", &months)
+
+	forecast, err := f.app.service.GetForecast(months)
+	if err != nil {
+		dialog.ShowError(err, f.app.window)
+		return
+	}
+	f.forecast = forecast
+
+	running := models.Zero(forecast.Currency)
+	f.running = make([]string, len(forecast.Months))
+	for i, month := range forecast.Months {
+		running = running.Add(month.Total)
+		f.running[i] = running.String()
+	}
+
+	if biggest, ok := forecast.BiggestMonth(); ok {
+		f.calloutLabel.SetText(fmt.Sprintf("Biggest month ahead: %s - %s (%s)",
+			biggest.Month.Format("January 2006"), biggest.Total, largestCharges(biggest, 2)))
+	} else {
+		f.calloutLabel.SetText("No charges due in this period.")
+	}
+
+	average := fmt.Sprintf("%s due over %d months, %s/month on average.", forecast.Total, len(forecast.Months), forecast.Average())
+	if len(forecast.MissingRates) > 0 {
+		average += fmt.Sprintf(" No exchange rate for %s - counted 1:1.", strings.Join(forecast.MissingRates, ", "))
+	}
+	f.averageLabel.SetText(average)
+
+	f.table.UnselectAll()
+	f.chargesLabel.SetText("Select a month to see its charges.")
+	f.table.Refresh()
+}
+
+func (f *ForecastView) cellText(id widget.TableCellID) string {
+	if f.forecast == nil || id.Row >= len(f.forecast.Months) {
+		return ""
+	}
+	month := f.forecast.Months[id.Row]
+
+	switch id.Col {
+	case 0:
+		return month.Month.Format("January 2006")
+	case 1:
+		return fmt.Sprintf("%d", len(month.Charges))
+	case 2:
+		return month.Total.String()
+	default:
+		return f.running[id.Row]
+	}
+}
+
+// showCharges lists the charges of the selected month
+func (f *ForecastView) showCharges(row int) {
+	if f.forecast == nil || row >= len(f.forecast.Months) {
+		return
+	}
+	month := f.forecast.Months[row]
+	if len(month.Charges) == 0 {
+		f.chargesLabel.SetText(fmt.Sprintf("Nothing due in %s.", month.Month.Format("January 2006")))
+		return
+	}
+
+	lines := make([]string, len(month.Charges))
+	for i, charge := range month.Charges {
+		lines[i] = fmt.Sprintf("%s   %s   %s", charge.Date.Format("Jan 2"), charge.Name, charge.Amount)
+	}
+	f.chargesLabel.SetText(strings.Join(lines, "\n"))
+}
+
+// largestCharges names the n most expensive charges of a month, e.g. "Adobe $239.88, Netflix $15.99"
+func largestCharges(month calculator.ForecastMonth, n int) string {
+	charges := append([]calculator.ForecastCharge(nil), month.Charges...)
+	sort.SliceStable(charges, func(i, j int) bool {
+		return charges[i].Amount.Amount > charges[j].Amount.Amount
+	})
+	if len(charges) > n {
+		charges = charges[:n]
+	}
+
+	parts := make([]string, len(charges))
+	for i, charge := range charges {
+		parts[i] = fmt.Sprintf("%s %s", charge.Name, charge.Amount)
+	}
+	return strings.Join(parts, ", ")
+}
@@ -1,6 +1,8 @@
 package ui
 
 import (
+	"errors"
+	"fmt"
 	"path/filepath"
 	"strconv"
 	"strings"
@@ -14,6 +16,7 @@ import (
 
 	"subman/internal/images"
 	"subman/internal/models"
+	"subman/internal/service"
 )
 
 type SubscriptionForm struct {
@@ -23,12 +26,30 @@ type SubscriptionForm struct {
 
 	nameEntry        *widget.Entry
 	costEntry        *widget.Entry
+	currencySelect   *widget.Select
+	priceDateEntry   *widget.Entry
+	introPriceEntry  *widget.Entry
+	introCountEntry  *widget.Entry
 	cycleSelect      *widget.Select
+	intervalEntry    *widget.Entry
+	unitSelect       *widget.Select
+	customInterval   *fyne.Container
 	categorySelect   *widget.Select
+	categories       []models.CategoryDef
+	tagsEntry        *widget.Entry
+	methodSelect     *widget.Select
+	methods          []models.PaymentMethod
+	splitSelect      *widget.Select
+	sharedWithEntry  *widget.Entry
+	paidByEntry      *widget.Entry
 	nextPaymentEntry *widget.Entry
 	startDateEntry   *widget.Entry
+	trialEndEntry    *widget.Entry
 	notesEntry       *widget.Entry
 	pausedCheck      *widget.Check
+	resumeEntry      *widget.Entry
+	cancelledEntry   *widget.Entry
+	accessEndsEntry  *widget.Entry
 	imageLabel       *widget.Label
 	selectedImage    string // Path to selected image file
 }
@@ -50,12 +71,63 @@ func (f *SubscriptionForm) buildForm() {
 	f.costEntry = widget.NewEntry()
 	f.costEntry.SetPlaceHolder("0.00")
 
-	f.cycleSelect = widget.NewSelect([]string{"monthly", "yearly"}, nil)
+	f.currencySelect = widget.NewSelect(models.Currencies, nil)
+	f.currencySelect.Selected = f.defaultCurrency()
+
+	// When editing, a changed cost is recorded in the price history from this date
+	f.priceDateEntry = widget.NewEntry()
+	f.priceDateEntry.SetPlaceHolder("YYYY-MM-DD (defaults to today, future dates are scheduled)")
+
+	// Promotional pricing for new subscriptions: intro price for N billing periods, then Cost
+	f.introPriceEntry = widget.NewEntry()
+	f.introPriceEntry.SetPlaceHolder("Intro price (optional)")
+	f.introCountEntry = widget.NewEntry()
+	f.introCountEntry.SetPlaceHolder("Periods")
+
+	// Custom interval inputs (e.g. every 2 weeks), only shown for the "custom" cycle
+	f.intervalEntry = widget.NewEntry()
+	f.intervalEntry.SetPlaceHolder("1")
+	f.unitSelect = widget.NewSelect([]string{"day", "week", "month", "year"}, nil)
+	f.unitSelect.Selected = "month"
+	f.customInterval = container.NewBorder(nil, nil, widget.NewLabel("Every"), f.unitSelect, f.intervalEntry)
+	f.customInterval.Hide()
+
+	cycles := []string{"weekly", "monthly", "quarterly", "semiannual", "yearly", "custom"}
+	f.cycleSelect = widget.NewSelect(cycles, func(s string) {
+		if s == string(models.Custom) {
+			f.customInterval.Show()
+		} else {
+			f.customInterval.Hide()
+		}
+	})
 	f.cycleSelect.Selected = "monthly"
 
-	categories := []string{"Streaming", "Software", "Utilities", "Gaming", "News", "Education", "Creator", "Other"}
-	f.categorySelect = widget.NewSelect(categories, nil)
-	f.categorySelect.Selected = "Other"
+	f.categories, _ = f.app.categoryService.List()
+	f.categorySelect = widget.NewSelect(categoryNames(f.categories), nil)
+	f.categorySelect.Selected = categoryName(f.categories, models.Other)
+
+	f.tagsEntry = widget.NewEntry()
+	f.tagsEntry.SetPlaceHolder("Comma separated, e.g. family, work-reimbursable")
+
+	f.methods, _ = f.app.methodService.List()
+	f.methodSelect = widget.NewSelect(paymentMethodOptions(f.methods), nil)
+	f.methodSelect.Selected = noPaymentMethod
+
+	// Sharing - the people listed split the cost with me
+	f.sharedWithEntry = widget.NewMultiLineEntry()
+	f.sharedWithEntry.SetPlaceHolder("One person per line; add \": 30\" for a percentage or fixed amount")
+	f.paidByEntry = widget.NewEntry()
+	f.paidByEntry.SetPlaceHolder("Paid by (leave empty if you pay)")
+	f.splitSelect = widget.NewSelect(splitOptions, func(value string) {
+		if value == splitNotShared {
+			f.sharedWithEntry.Hide()
+			f.paidByEntry.Hide()
+		} else {
+			f.sharedWithEntry.Show()
+			f.paidByEntry.Show()
+		}
+	})
+	f.splitSelect.SetSelected(splitNotShared)
 
 	f.nextPaymentEntry = widget.NewEntry()
 	f.nextPaymentEntry.SetPlaceHolder("YYYY-MM-DD")
@@ -63,11 +135,28 @@ func (f *SubscriptionForm) buildForm() {
 	f.startDateEntry = widget.NewEntry()
 	f.startDateEntry.SetPlaceHolder("YYYY-MM-DD")
 
+	f.trialEndEntry = widget.NewEntry()
+	f.trialEndEntry.SetPlaceHolder("YYYY-MM-DD (optional, free until this date)")
+
 	f.notesEntry = widget.NewMultiLineEntry()
 	f.notesEntry.SetPlaceHolder("Additional notes...")
 	f.notesEntry.Wrapping = fyne.TextWrapWord // Wrap at word boundaries, no horizontal scroll
 
-	f.pausedCheck = widget.NewCheck("Subscription is paused", nil)
+	f.resumeEntry = widget.NewEntry()
+	f.resumeEntry.SetPlaceHolder("Resume on YYYY-MM-DD (optional)")
+	f.resumeEntry.Hide()
+	f.pausedCheck = widget.NewCheck("Subscription is paused", func(checked bool) {
+		if checked {
+			f.resumeEntry.Show()
+		} else {
+			f.resumeEntry.Hide()
+		}
+	})
+
+	f.cancelledEntry = widget.NewEntry()
+	f.cancelledEntry.SetPlaceHolder("YYYY-MM-DD (leave empty while active)")
+	f.accessEndsEntry = widget.NewEntry()
+	f.accessEndsEntry.SetPlaceHolder("YYYY-MM-DD (optional)")
 
 	// Image picker
 	f.imageLabel = widget.NewLabel("No image selected (will use default)")
@@ -78,15 +167,37 @@ func (f *SubscriptionForm) buildForm() {
 	// Populate if editing
 	if f.subscription != nil {
 		f.nameEntry.SetText(f.subscription.Name)
-		f.costEntry.SetText(strconv.FormatFloat(f.subscription.Cost, 'f', 2, 64))
-		f.cycleSelect.Selected = string(f.subscription.BillingCycle)
-		// Capitalize the category for display
-		catStr := string(f.subscription.Category)
-		f.categorySelect.Selected = strings.Title(catStr)
+		f.costEntry.SetText(f.subscription.BilledCost().Decimal())
+		f.currencySelect.Selected = f.subscription.Cost.CurrencyCode()
+		f.cycleSelect.SetSelected(string(f.subscription.BillingCycle))
+		if f.subscription.CustomInterval != nil {
+			f.intervalEntry.SetText(strconv.Itoa(f.subscription.CustomInterval.Count))
+			f.unitSelect.Selected = string(f.subscription.CustomInterval.Unit)
+		}
+		f.categorySelect.Selected = categoryName(f.categories, f.subscription.Category)
+		f.tagsEntry.SetText(strings.Join(f.subscription.Tags, ", "))
+		f.methodSelect.Selected = paymentMethodLabel(f.methods, f.subscription.PaymentMethodID)
+		if f.subscription.IsShared() {
+			f.splitSelect.SetSelected(splitLabel(f.subscription.Sharing.Split))
+			f.sharedWithEntry.SetText(formatParticipants(f.subscription.Sharing))
+			f.paidByEntry.SetText(f.subscription.Sharing.PaidBy)
+		}
 		f.nextPaymentEntry.SetText(f.subscription.NextPayment.Format("2006-01-02"))
 		f.startDateEntry.SetText(f.subscription.StartDate.Format("2006-01-02"))
+		if f.subscription.HasTrial() {
+			f.trialEndEntry.SetText(f.subscription.TrialEndDate.Format("2006-01-02"))
+		}
+		if f.subscription.IsCancelled() {
+			f.cancelledEntry.SetText(f.subscription.CancelledAt.Format("2006-01-02"))
+		}
+		if !f.subscription.AccessEndsAt.IsZero() {
+			f.accessEndsEntry.SetText(f.subscription.AccessEndsAt.Format("2006-01-02"))
+		}
 		f.notesEntry.SetText(f.subscription.Notes)
-		f.pausedCheck.Checked = f.subscription.Paused
+		f.pausedCheck.SetChecked(f.subscription.Paused)
+		if resume := f.subscription.PausedUntil(); !resume.IsZero() {
+			f.resumeEntry.SetText(resume.Format("2006-01-02"))
+		}
 
 		if f.subscription.Image != "" {
 			f.selectedImage = f.subscription.Image
@@ -96,15 +207,29 @@ func (f *SubscriptionForm) buildForm() {
 
 	formItems := []*widget.FormItem{
 		widget.NewFormItem("Name", f.nameEntry),
-		widget.NewFormItem("Cost", f.costEntry),
-		widget.NewFormItem("Billing Cycle", f.cycleSelect),
+		widget.NewFormItem("Cost", container.NewBorder(nil, nil, nil, f.currencySelect, f.costEntry)),
+		widget.NewFormItem("Billing Cycle", container.NewVBox(f.cycleSelect, f.customInterval)),
+	}
+	if f.subscription != nil {
+		formItems = append(formItems, widget.NewFormItem("Price Changed On", f.priceDateEntry))
+	} else {
+		introPrice := container.NewGridWithColumns(2, f.introPriceEntry, f.introCountEntry)
+		formItems = append(formItems, widget.NewFormItem("Intro Offer", introPrice))
+	}
+	formItems = append(formItems,
 		widget.NewFormItem("Category", f.categorySelect),
+		widget.NewFormItem("Tags", f.tagsEntry),
+		widget.NewFormItem("Payment Method", f.methodSelect),
+		widget.NewFormItem("Shared", container.NewVBox(f.splitSelect, f.sharedWithEntry, f.paidByEntry)),
 		widget.NewFormItem("Image", imageSelector),
 		widget.NewFormItem("Next Payment", f.nextPaymentEntry),
 		widget.NewFormItem("Start Date", f.startDateEntry),
+		widget.NewFormItem("Trial Ends", f.trialEndEntry),
 		widget.NewFormItem("Notes", f.notesEntry),
-		widget.NewFormItem("Status", f.pausedCheck),
-	}
+		widget.NewFormItem("Status", container.NewVBox(f.pausedCheck, f.resumeEntry)),
+		widget.NewFormItem("Cancelled On", f.cancelledEntry),
+		widget.NewFormItem("Access Ends", f.accessEndsEntry),
+	)
 
 	formWidget := widget.NewForm(formItems...)
 	formWidget.OnSubmit = f.onSubmit
@@ -125,7 +250,7 @@ func (f *SubscriptionForm) Show() {
 
 func (f *SubscriptionForm) onSubmit() {
 	// Validate and parse
-	cost, err := strconv.ParseFloat(f.costEntry.Text, 64)
+	cost, err := models.ParseMoney(f.costEntry.Text, f.currencySelect.Selected)
 	if err != nil {
 		dialog.ShowError(err, f.app.window)
 		return
@@ -143,36 +268,209 @@ func (f *SubscriptionForm) onSubmit() {
 		return
 	}
 
+	// Optional free trial - the entered cost becomes the post-trial price
+	var trialEnd time.Time
+	if text := strings.TrimSpace(f.trialEndEntry.Text); text != "" {
+		trialEnd, err = time.Parse("2006-01-02", text)
+		if err != nil {
+			dialog.ShowError(err, f.app.window)
+			return
+		}
+	}
+
+	priceEffective := time.Now()
+	if text := strings.TrimSpace(f.priceDateEntry.Text); text != "" {
+		priceEffective, err = time.Parse("2006-01-02", text)
+		if err != nil {
+			dialog.ShowError(err, f.app.window)
+			return
+		}
+	}
+
+	// Optional auto-resume date for a pause
+	var resumeDate time.Time
+	if text := strings.TrimSpace(f.resumeEntry.Text); f.pausedCheck.Checked && text != "" {
+		resumeDate, err = time.Parse("2006-01-02", text)
+		if err != nil {
+			dialog.ShowError(err, f.app.window)
+			return
+		}
+	}
+
+	// Optional cancellation - payments stop after this date
+	var cancelledAt, accessEndsAt time.Time
+	if text := strings.TrimSpace(f.cancelledEntry.Text); text != "" {
+		cancelledAt, err = time.Parse("2006-01-02", text)
+		if err != nil {
+			dialog.ShowError(err, f.app.window)
+			return
+		}
+	}
+	if text := strings.TrimSpace(f.accessEndsEntry.Text); text != "" {
+		accessEndsAt, err = time.Parse("2006-01-02", text)
+		if err != nil {
+			dialog.ShowError(err, f.app.window)
+			return
+		}
+	}
+
+	cycle := models.BillingCycle(f.cycleSelect.Selected)
+	var customInterval *models.BillingInterval
+	if cycle == models.Custom {
+		count, err := strconv.Atoi(strings.TrimSpace(f.intervalEntry.Text))
+		if err != nil || count < 1 {
+			dialog.ShowError(fmt.Errorf("custom interval must be a whole number of at least 1"), f.app.window)
+			return
+		}
+		customInterval = &models.BillingInterval{
+			Unit:  models.IntervalUnit(f.unitSelect.Selected),
+			Count: count,
+		}
+	}
+
+	sharing, err := parseSharing(f.splitSelect.Selected, f.sharedWithEntry.Text, f.paidByEntry.Text, cost)
+	if err != nil {
+		dialog.ShowError(err, f.app.window)
+		return
+	}
+
 	// Handle image - use selected image, or default for category if none selected
+	// A category's default icon follows the category when it changes
+	category := categoryIDForName(f.categories, f.categorySelect.Selected)
 	imageFilename := f.selectedImage
-	if imageFilename == "" {
-		category := models.Category(strings.ToLower(f.categorySelect.Selected))
+	if imageFilename == "" || (f.subscription != nil && imageFilename == images.GetDefaultImageForCategory(f.subscription.Category)) {
 		imageFilename = images.GetDefaultImageForCategory(category)
 	}
 
 	sub := &models.Subscription{
-		Name:         f.nameEntry.Text,
-		Cost:         cost,
-		BillingCycle: models.BillingCycle(f.cycleSelect.Selected),
-		Category:     models.Category(strings.ToLower(f.categorySelect.Selected)),
-		NextPayment:  nextPayment,
-		StartDate:    startDate,
-		Notes:        f.notesEntry.Text,
-		Image:        imageFilename,
-		Paused:       f.pausedCheck.Checked,
+		Name:            f.nameEntry.Text,
+		Cost:            cost,
+		BillingCycle:    cycle,
+		CustomInterval:  customInterval,
+		Category:        category,
+		Tags:            models.ParseTags(f.tagsEntry.Text),
+		PaymentMethodID: paymentMethodIDForLabel(f.methods, f.methodSelect.Selected),
+		Sharing:         sharing,
+		NextPayment:     nextPayment,
+		StartDate:       startDate,
+		Notes:           f.notesEntry.Text,
+		Image:           imageFilename,
+		TrialEndDate:    trialEnd,
+		CancelledAt:     cancelledAt,
+		AccessEndsAt:    accessEndsAt,
+	}
+
+	// Record the pause (or resume) in the subscription's pause history
+	if f.subscription != nil {
+		sub.Pauses = f.subscription.Pauses
+		sub.Paused = f.subscription.Paused
+		sub.UpdatedAt = f.subscription.UpdatedAt // Dates a pause from before pause history
+	}
+	sub.SetPaused(f.pausedCheck.Checked, resumeDate, time.Now())
+
+	if sub.InTrial(time.Now()) {
+		sub.PostTrialCost = cost
+		sub.Cost = models.Zero(cost.Currency)
+	}
+
+	// Intro offer: promotional price first, then the regular cost
+	if f.subscription == nil && strings.TrimSpace(f.introPriceEntry.Text) != "" {
+		introPrice, err := models.ParseMoney(f.introPriceEntry.Text, cost.Currency)
+		if err != nil {
+			dialog.ShowError(err, f.app.window)
+			return
+		}
+		periods, err := strconv.Atoi(strings.TrimSpace(f.introCountEntry.Text))
+		if err != nil || periods < 1 {
+			dialog.ShowError(fmt.Errorf("intro offer needs the number of billing periods it lasts"), f.app.window)
+			return
+		}
+		sub.SetIntroPrice(introPrice, periods, cost)
+		sub.ApplyPriceSchedule(time.Now())
 	}
 
 	if f.subscription != nil {
-		// Update existing
 		sub.ID = f.subscription.ID
-		f.app.service.Update(sub)
-	} else {
-		// Create new
-		f.app.service.Create(sub)
 	}
 
-	f.app.Refresh()
-	f.dialog.Hide()
+	// A failed save keeps the form open, so the changes can be retried after the
+	// data is reloaded
+	var submit func()
+	submit = func() {
+		err := f.save(sub, priceEffective)
+		if errors.Is(err, service.ErrSubscriptionChanged) {
+			f.app.reload()
+			f.confirmOverwrite(sub, submit)
+			return
+		}
+		if err != nil {
+			dialog.ShowError(err, f.app.window)
+			f.app.reload()
+			return
+		}
+		f.app.Refresh()
+		f.dialog.Hide()
+	}
+
+	// Warn before a save that would take a budget over its limit
+	exceeded, err := f.app.budgetService.CheckSubscription(sub)
+	if err != nil || len(exceeded) == 0 {
+		submit()
+		return
+	}
+
+	categories, _ := f.app.categoryService.List()
+	lines := make([]string, len(exceeded))
+	for i, status := range exceeded {
+		lines[i] = fmt.Sprintf("%s: %s/mo of %s (%.0f%%)",
+			budgetLabel(categories, status.Budget), status.Planned, status.Limit, status.PlannedPercent)
+	}
+	message := "Saving this subscription goes over budget:\n" + strings.Join(lines, "\n") + "\n\nSave anyway?"
+	dialog.ShowConfirm("Over Budget", message, func(ok bool) {
+		if ok {
+			submit()
+		}
+	}, f.app.window)
+}
+
+// save stores the subscription built by onSubmit
+func (f *SubscriptionForm) save(sub *models.Subscription, priceEffective time.Time) error {
+	if f.subscription != nil {
+		// Update existing
+		return f.app.service.UpdateEffective(sub, priceEffective)
+	}
+	// Create new
+	return f.app.service.Create(sub)
+}
+
+// confirmOverwrite tells the user the subscription was changed elsewhere while
+// the form was open, and calls save again if they choose to keep their version
+func (f *SubscriptionForm) confirmOverwrite(sub *models.Subscription, save func()) {
+	message := "This subscription was changed elsewhere, for example by another copy of Subman " +
+		"or a sync tool, since you opened it.\n\nSave your version over those changes? " +
+		"Choose No and reopen the subscription to see them."
+	dialog.ShowConfirm("Subscription Changed", message, func(ok bool) {
+		if !ok {
+			return
+		}
+		current, err := f.app.service.Get(sub.ID)
+		if err != nil {
+			dialog.ShowError(err, f.app.window)
+			return
+		}
+		f.subscription = current
+		sub.UpdatedAt = current.UpdatedAt
+		save()
+	}, f.app.window)
+}
+
+// defaultCurrency returns the base currency for new subscriptions
+func (f *SubscriptionForm) defaultCurrency() string {
+	rates, err := f.app.service.GetExchangeRates()
+	if err != nil {
+		return models.DefaultCurrency
+	}
+	return rates.BaseCurrency()
 }
 
 func (f *SubscriptionForm) onCancel() {
@@ -217,3 +515,109 @@ func (f *SubscriptionForm) clearImage() {
 	f.selectedImage = ""
 	f.imageLabel.SetText("No image selected (will use default)")
 }
+
+const splitNotShared = "Not shared"
+
+var splitOptions = []string{splitNotShared, "Split equally", "By percentage", "Fixed amounts"}
+
+var splitTypes = map[string]models.SplitType{
+	"Split equally": models.SplitEqual,
+	"By percentage": models.SplitPercent,
+	"Fixed amounts": models.SplitFixed,
+}
+
+func splitLabel(split models.SplitType) string {
+	for label, t := range splitTypes {
+		if t == split {
+			return label
+		}
+	}
+	return "Split equally"
+}
+
+// formatParticipants writes participants back in the "Name: value" form the entry accepts
+func formatParticipants(sharing *models.Sharing) string {
+	lines := make([]string, len(sharing.Participants))
+	for i, p := range sharing.Participants {
+		switch sharing.Split {
+		case models.SplitPercent:
+			lines[i] = fmt.Sprintf("%s: %s", p.Name, strconv.FormatFloat(p.Percent, 'f', -1, 64))
+		case models.SplitFixed:
+			lines[i] = fmt.Sprintf("%s: %s", p.Name, p.Fixed.Decimal())
+		default:
+			lines[i] = p.Name
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// parseSharing reads the sharing section of the form
+// Returns nil when the subscription isn't shared
+// Fixed shares may add up to at most cost, the price per billing period
+func parseSharing(splitLabel, participantsText, paidBy string, cost models.Money) (*models.Sharing, error) {
+	split, shared := splitTypes[splitLabel]
+	if !shared {
+		return nil, nil
+	}
+
+	sharing := &models.Sharing{Split: split, PaidBy: strings.TrimSpace(paidBy)}
+	if strings.EqualFold(sharing.PaidBy, models.Me) {
+		sharing.PaidBy = ""
+	}
+
+	totalPercent := 0.0
+	totalFixed := models.Zero(cost.Currency)
+	for _, line := range strings.Split(participantsText, "\n") {
+		name, value, hasValue := strings.Cut(line, ":")
+		name = strings.TrimSpace(name)
+		value = strings.TrimSpace(value)
+		if name == "" {
+			continue
+		}
+		if strings.EqualFold(name, models.Me) {
+			return nil, fmt.Errorf("list only the other people sharing; your share is what's left")
+		}
+
+		p := models.Participant{Name: name}
+		switch split {
+		case models.SplitPercent:
+			percent, err := strconv.ParseFloat(value, 64)
+			if !hasValue || err != nil || percent < 0 || percent > 100 {
+				return nil, fmt.Errorf("enter a percentage for %s, e.g. \"%s: 25\"", name, name)
+			}
+			p.Percent = percent
+			totalPercent += percent
+		case models.SplitFixed:
+			amount, err := models.ParseMoney(value, cost.Currency)
+			if !hasValue || err != nil || amount.Amount < 0 {
+				return nil, fmt.Errorf("enter an amount for %s, e.g. \"%s: 5.00\"", name, name)
+			}
+			p.Fixed = amount
+			totalFixed = totalFixed.Add(amount)
+		}
+		sharing.Participants = append(sharing.Participants, p)
+	}
+
+	if len(sharing.Participants) == 0 {
+		return nil, fmt.Errorf("list at least one person the subscription is shared with")
+	}
+	if totalPercent > 100 {
+		return nil, fmt.Errorf("percentages add up to more than 100%%")
+	}
+	if totalFixed.Amount > cost.Amount {
+		return nil, fmt.Errorf("shares add up to %s, more than the %s the subscription costs", totalFixed, cost)
+	}
+	if sharing.PaidBy != "" {
+		found := false
+		for _, p := range sharing.Participants {
+			if p.Name == sharing.PaidBy {
+				found = true
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("%s pays for the subscription but isn't listed as sharing it", sharing.PaidBy)
+		}
+	}
+
+	return sharing, nil
+}
@@ -35,8 +35,8 @@ func (i *ImportView) Show() {
 		i.importBundle(filePath)
 	}, i.app.window)
 
-	// Filter for ZIP files
-	fileDialog.SetFilter(storage.NewExtensionFileFilter([]string{".zip"}))
+	// Filter for ZIP files and encrypted bundles
+	fileDialog.SetFilter(storage.NewExtensionFileFilter([]string{".zip", importer.EncryptedBundleExt}))
 	fileDialog.Show()
 }
 
@@ -51,6 +51,25 @@ func (i *ImportView) importBundle(zipPath string) {
 	// Create importer
 	bundleImporter := importer.NewBundleImporter(imagesDir)
 
+	// Encrypted bundles need their passphrase before they can be checked
+	encrypted, err := importer.IsEncryptedBundle(zipPath)
+	if err != nil {
+		dialog.ShowError(fmt.Errorf("invalid bundle: %w", err), i.app.window)
+		return
+	}
+	if encrypted {
+		showPassphrasePrompt("Encrypted Bundle", false, i.app.window, func(passphrase string) {
+			bundleImporter.SetPassphrase(passphrase)
+			i.validateBundle(zipPath, bundleImporter)
+		})
+		return
+	}
+
+	i.validateBundle(zipPath, bundleImporter)
+}
+
+// validateBundle checks the bundle before asking how to import it
+func (i *ImportView) validateBundle(zipPath string, bundleImporter *importer.BundleImporter) {
 	// Validate the bundle first
 	if err := bundleImporter.ValidateBundle(zipPath); err != nil {
 		dialog.ShowError(fmt.Errorf("invalid bundle: %w", err), i.app.window)
@@ -123,15 +142,29 @@ func (i *ImportView) doImport(zipPath string, bundleImporter *importer.BundleImp
 		// Merge payments
 		currentList.Payments = append(currentList.Payments, importedList.Payments...)
 
+		// Merge categories the current registry doesn't have yet
+		currentList.EnsureCategories()
+		for _, cat := range importedList.Categories {
+			if _, ok := currentList.FindCategory(cat.ID); !ok {
+				currentList.Categories = append(currentList.Categories, cat)
+			}
+		}
+
 		if err := storage.Save(currentList); err != nil {
 			dialog.ShowError(fmt.Errorf("failed to save merged data: %w", err), i.app.window)
 			return
 		}
 	}
 
-	// Regenerate payments after import
-	if err := i.app.paymentService.GenerateAllPayments(); err != nil {
-		dialog.ShowError(fmt.Errorf("failed to regenerate payment history: %w", err), i.app.window)
+	// Register imported categories and give them icons
+	if err := i.app.prepareCategories(); err != nil {
+		dialog.ShowError(err, i.app.window)
+		return
+	}
+
+	// Apply scheduled changes and regenerate payments after import
+	if err := i.app.applyScheduledChanges(); err != nil {
+		dialog.ShowError(err, i.app.window)
 		return
 	}
 
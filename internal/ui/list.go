@@ -67,6 +67,16 @@ func (l *ListView) Refresh() {
 	}
 
 	l.subscriptions = subs
+
+	categories, err := l.app.categoryService.List()
+	if err != nil {
+		return
+	}
+
+	methods, err := l.app.methodService.List()
+	if err != nil {
+		return
+	}
 	l.listContainer.Objects = nil
 
 	if len(subs) == 0 {
@@ -83,7 +93,15 @@ func (l *ListView) Refresh() {
 			if i%2 == 1 {
 				bgColor = altColor
 			}
-			card := components.NewSubscriptionCard(sub, l.onEdit, l.onDelete, bgColor)
+			category := findCategory(categories, sub.Category)
+			var method *models.PaymentMethod
+			for _, m := range methods {
+				if m.ID == sub.PaymentMethodID {
+					method = &m
+					break
+				}
+			}
+			card := components.NewSubscriptionCard(sub, category, method, l.onEdit, l.onDelete, l.onHistory, bgColor)
 			l.listContainer.Add(card)
 		}
 	}
@@ -101,6 +119,11 @@ func (l *ListView) onEdit(sub models.Subscription) {
 	form.Show()
 }
 
+func (l *ListView) onHistory(sub models.Subscription) {
+	history := NewPaymentHistoryView(l.app, sub)
+	history.Show()
+}
+
 func (l *ListView) onDelete(sub models.Subscription) {
 	confirm := dialog.NewConfirm(
 		"Delete Subscription",
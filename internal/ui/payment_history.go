@@ -0,0 +1,298 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"subman/internal/models"
+	"subman/pkg/calculator"
+)
+
+var paymentHistoryColumns = []string{"Date", "Status", "Amount", "Notes", "Source", "Payment Method"}
+
+// paymentTypeMarkers prefix each payment type so it stands out in listings
+var paymentTypeMarkers = map[models.PaymentType]string{
+	models.PaymentCharged:  "✓",
+	models.PaymentRefunded: "↩",
+	models.PaymentCredit:   "+",
+	models.PaymentFailed:   "✗",
+	models.PaymentPending:  "…",
+}
+
+// paymentTypeLabel returns the marked-up status of a payment, e.g. "↩ refunded"
+func paymentTypeLabel(t models.PaymentType) string {
+	return paymentTypeMarkers[t] + " " + string(t)
+}
+
+// paymentTypeImportance colours a payment's status and amount
+func paymentTypeImportance(t models.PaymentType) widget.Importance {
+	switch t {
+	case models.PaymentRefunded, models.PaymentCredit:
+		return widget.SuccessImportance
+	case models.PaymentFailed:
+		return widget.DangerImportance
+	case models.PaymentPending:
+		return widget.WarningImportance
+	default:
+		return widget.MediumImportance
+	}
+}
+
+// PaymentHistoryView shows and edits the payment history of one subscription
+type PaymentHistoryView struct {
+	app          *App
+	subscription models.Subscription
+	payments     []models.Payment
+	methods      []models.PaymentMethod
+	selected     int
+	table        *widget.Table
+	summaryLabel *widget.Label
+	editBtn      *widget.Button
+	deleteBtn    *widget.Button
+}
+
+func NewPaymentHistoryView(app *App, sub models.Subscription) *PaymentHistoryView {
+	return &PaymentHistoryView{
+		app:          app,
+		subscription: sub,
+		selected:     -1,
+	}
+}
+
+func (h *PaymentHistoryView) Show() {
+	h.summaryLabel = widget.NewLabel("")
+	h.summaryLabel.TextStyle = fyne.TextStyle{Bold: true}
+
+	h.table = widget.NewTableWithHeaders(
+		func() (int, int) {
+			return len(h.payments), len(paymentHistoryColumns)
+		},
+		func() fyne.CanvasObject {
+			return widget.NewLabel("")
+		},
+		func(id widget.TableCellID, cell fyne.CanvasObject) {
+			label := cell.(*widget.Label)
+			label.Importance = widget.MediumImportance
+			if (id.Col == 1 || id.Col == 2) && id.Row < len(h.payments) {
+				label.Importance = paymentTypeImportance(h.payments[id.Row].Type)
+			}
+			label.SetText(h.cellText(id))
+		},
+	)
+	h.table.ShowHeaderColumn = false
+	h.table.UpdateHeader = func(id widget.TableCellID, cell fyne.CanvasObject) {
+		if id.Col >= 0 {
+			cell.(*widget.Label).SetText(paymentHistoryColumns[id.Col])
+		}
+	}
+	for col, width := range []float32{110, 110, 110, 200, 90, 170} {
+		h.table.SetColumnWidth(col, width)
+	}
+	h.table.OnSelected = func(id widget.TableCellID) {
+		h.selected = id.Row
+		h.editBtn.Enable()
+		h.deleteBtn.Enable()
+	}
+
+	addBtn := widget.NewButton("Add Payment", func() {
+		h.showPaymentDialog(nil)
+	})
+	h.editBtn = widget.NewButton("Edit", func() {
+		if h.selected >= 0 && h.selected < len(h.payments) {
+			payment := h.payments[h.selected]
+			h.showPaymentDialog(&payment)
+		}
+	})
+	h.deleteBtn = widget.NewButton("Delete", func() {
+		if h.selected >= 0 && h.selected < len(h.payments) {
+			h.confirmDelete(h.payments[h.selected])
+		}
+	})
+
+	h.refresh()
+
+	content := container.NewBorder(
+		h.summaryLabel,
+		container.NewHBox(addBtn, h.editBtn, h.deleteBtn),
+		nil,
+		nil,
+		h.table,
+	)
+
+	d := dialog.NewCustom("Payment History - "+h.subscription.Name, "Close", content, h.app.window)
+	d.Resize(fyne.NewSize(760, 500))
+	d.Show()
+}
+
+func (h *PaymentHistoryView) refresh() {
+	payments, err := h.app.paymentService.GetPaymentsForSubscription(h.subscription.ID)
+	if err != nil {
+		dialog.ShowError(err, h.app.window)
+		return
+	}
+	h.payments = payments
+	h.methods, _ = h.app.methodService.List()
+
+	rates, err := h.app.service.GetExchangeRates()
+	if err != nil {
+		dialog.ShowError(err, h.app.window)
+		return
+	}
+	summary := calculator.SummarizePayments(payments, rates, time.Now())
+	if summary.Count == 0 {
+		h.summaryLabel.SetText("No payments recorded yet")
+	} else {
+		h.summaryLabel.SetText(fmt.Sprintf("Lifetime total: %s over %d payments    Average: %s/month since %s",
+			summary.Total, summary.Count, summary.MonthlyAverage, summary.FirstPayment.Format("Jan 2006")))
+	}
+
+	h.selected = -1
+	h.table.UnselectAll()
+	h.editBtn.Disable()
+	h.deleteBtn.Disable()
+	h.table.Refresh()
+}
+
+func (h *PaymentHistoryView) cellText(id widget.TableCellID) string {
+	if id.Row >= len(h.payments) {
+		return ""
+	}
+	payment := h.payments[id.Row]
+
+	switch id.Col {
+	case 0:
+		return payment.PaymentDate.Format("2006-01-02")
+	case 1:
+		return paymentTypeLabel(payment.Type)
+	case 2:
+		return payment.Amount.String()
+	case 3:
+		return payment.Notes
+	case 4:
+		return string(payment.Source)
+	default:
+		if label := paymentMethodLabel(h.methods, payment.PaymentMethodID); label != noPaymentMethod {
+			return label
+		}
+		return ""
+	}
+}
+
+// showPaymentDialog edits a payment, or adds one when payment is nil
+func (h *PaymentHistoryView) showPaymentDialog(payment *models.Payment) {
+	editing := models.Payment{
+		SubscriptionID:  h.subscription.ID,
+		Amount:          h.subscription.PriceAt(time.Now()),
+		PaymentDate:     time.Now(),
+		PaymentMethodID: h.subscription.PaymentMethodID,
+		Type:            models.PaymentCharged,
+	}
+	if payment != nil {
+		editing = *payment
+	}
+
+	dateEntry := widget.NewEntry()
+	dateEntry.SetPlaceHolder("YYYY-MM-DD")
+	dateEntry.SetText(editing.PaymentDate.Format("2006-01-02"))
+
+	var typeOptions []string
+	for _, t := range models.PaymentTypes {
+		typeOptions = append(typeOptions, string(t))
+	}
+	typeSelect := widget.NewSelect(typeOptions, nil)
+	typeSelect.Selected = string(editing.Type)
+
+	// Amounts are entered unsigned; the type decides the sign
+	amountEntry := widget.NewEntry()
+	amountEntry.SetText(strings.TrimPrefix(editing.Amount.Decimal(), "-"))
+	currencySelect := widget.NewSelect(models.Currencies, nil)
+	currencySelect.Selected = editing.Amount.CurrencyCode()
+
+	notesEntry := widget.NewEntry()
+	notesEntry.SetPlaceHolder("e.g. One-off upgrade charge")
+	notesEntry.SetText(editing.Notes)
+
+	methodSelect := widget.NewSelect(paymentMethodOptions(h.methods), nil)
+	methodSelect.Selected = paymentMethodLabel(h.methods, editing.PaymentMethodID)
+
+	form := widget.NewForm(
+		widget.NewFormItem("Date", dateEntry),
+		widget.NewFormItem("Status", typeSelect),
+		widget.NewFormItem("Amount", container.NewBorder(nil, nil, nil, currencySelect, amountEntry)),
+		widget.NewFormItem("Notes", notesEntry),
+		widget.NewFormItem("Payment Method", methodSelect),
+	)
+
+	title := "Add Payment"
+	if payment != nil {
+		title = "Edit Payment"
+	}
+
+	d := dialog.NewCustomConfirm(title, "Save", "Cancel", form, func(ok bool) {
+		if !ok {
+			return
+		}
+
+		// Parsed as UTC like the subscription form, so it lines up with billing dates
+		date, err := time.Parse("2006-01-02", strings.TrimSpace(dateEntry.Text))
+		if err != nil {
+			dialog.ShowError(fmt.Errorf("date must be YYYY-MM-DD"), h.app.window)
+			return
+		}
+		amount, err := models.ParseMoney(amountEntry.Text, currencySelect.Selected)
+		if err != nil {
+			dialog.ShowError(err, h.app.window)
+			return
+		}
+
+		// Keep the exact time of an unchanged date so the payment stays on its billing date
+		if date.Format("2006-01-02") != editing.PaymentDate.Format("2006-01-02") {
+			editing.PaymentDate = date
+		}
+		editing.Amount = amount
+		editing.Type = models.PaymentType(typeSelect.Selected)
+		editing.Notes = notesEntry.Text
+		editing.PaymentMethodID = paymentMethodIDForLabel(h.methods, methodSelect.Selected)
+
+		if payment == nil {
+			err = h.app.paymentService.AddPayment(&editing)
+		} else {
+			err = h.app.paymentService.UpdatePayment(&editing)
+		}
+		if err != nil {
+			dialog.ShowError(err, h.app.window)
+			return
+		}
+
+		h.app.Refresh()
+		h.refresh()
+	}, h.app.window)
+	d.Resize(fyne.NewSize(420, 0))
+	d.Show()
+}
+
+func (h *PaymentHistoryView) confirmDelete(payment models.Payment) {
+	message := fmt.Sprintf("Delete the %s payment of %s?", payment.PaymentDate.Format("Jan 2, 2006"), payment.Amount)
+	if payment.IsGenerated() {
+		message += "\nIt won't be generated again."
+	}
+
+	confirm := dialog.NewConfirm("Delete Payment", message, func(ok bool) {
+		if !ok {
+			return
+		}
+		if err := h.app.paymentService.DeletePayment(payment.ID); err != nil {
+			dialog.ShowError(err, h.app.window)
+			return
+		}
+		h.app.Refresh()
+		h.refresh()
+	}, h.app.window)
+	confirm.Show()
+}
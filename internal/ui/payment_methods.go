@@ -0,0 +1,206 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"subman/internal/models"
+)
+
+// noPaymentMethod is the select option for subscriptions without a payment method
+const noPaymentMethod = "None"
+
+var paymentMethodTypes = []string{
+	string(models.CardMethod),
+	string(models.BankMethod),
+	string(models.PayPalMethod),
+	string(models.OtherMethod),
+}
+
+// PaymentMethodsView lets the user maintain the cards and accounts subscriptions bill to
+type PaymentMethodsView struct {
+	app           *App
+	listContainer *fyne.Container
+}
+
+func NewPaymentMethodsView(app *App) *PaymentMethodsView {
+	return &PaymentMethodsView{
+		app: app,
+	}
+}
+
+func (p *PaymentMethodsView) Show() {
+	p.listContainer = container.NewVBox()
+	p.refresh()
+
+	addBtn := widget.NewButton("Add Payment Method", func() {
+		p.showEditDialog(nil)
+	})
+
+	scroll := container.NewVScroll(p.listContainer)
+	scroll.SetMinSize(fyne.NewSize(460, 300))
+
+	d := dialog.NewCustom("Payment Methods", "Close", container.NewBorder(nil, addBtn, nil, nil, scroll), p.app.window)
+	d.Show()
+}
+
+func (p *PaymentMethodsView) refresh() {
+	methods, err := p.app.methodService.List()
+	if err != nil {
+		dialog.ShowError(err, p.app.window)
+		return
+	}
+
+	p.listContainer.Objects = nil
+	if len(methods) == 0 {
+		p.listContainer.Add(widget.NewLabel("No payment methods yet."))
+	}
+
+	for _, method := range methods {
+		method := method
+		label := widget.NewLabel(fmt.Sprintf("%s - %s", method, method.Type))
+		if method.ExpiresWithin(time.Now(), 0) {
+			label.SetText(label.Text + " - EXPIRED")
+			label.Importance = widget.DangerImportance
+		}
+
+		editBtn := widget.NewButton("Edit", func() {
+			p.showEditDialog(&method)
+		})
+		deleteBtn := widget.NewButton("Delete", func() {
+			p.confirmDelete(method)
+		})
+
+		p.listContainer.Add(container.NewBorder(nil, nil, nil, container.NewHBox(editBtn, deleteBtn), label))
+	}
+	p.listContainer.Refresh()
+}
+
+// showEditDialog edits an existing payment method, or creates one when method is nil
+func (p *PaymentMethodsView) showEditDialog(method *models.PaymentMethod) {
+	editing := models.PaymentMethod{Type: models.CardMethod}
+	if method != nil {
+		editing = *method
+	}
+
+	labelEntry := widget.NewEntry()
+	labelEntry.SetPlaceHolder("e.g. Work Visa")
+	labelEntry.SetText(editing.Label)
+
+	typeSelect := widget.NewSelect(paymentMethodTypes, nil)
+	typeSelect.Selected = string(editing.Type)
+
+	lastFourEntry := widget.NewEntry()
+	lastFourEntry.SetPlaceHolder("1234 (optional)")
+	lastFourEntry.SetText(editing.LastFour)
+
+	expiryEntry := widget.NewEntry()
+	expiryEntry.SetPlaceHolder("MM/YYYY (optional)")
+	if !editing.ExpiryMonth.IsZero() {
+		expiryEntry.SetText(editing.ExpiryMonth.Format("01/2006"))
+	}
+
+	form := widget.NewForm(
+		widget.NewFormItem("Label", labelEntry),
+		widget.NewFormItem("Type", typeSelect),
+		widget.NewFormItem("Last Four", lastFourEntry),
+		widget.NewFormItem("Expires", expiryEntry),
+	)
+
+	title := "Add Payment Method"
+	if method != nil {
+		title = "Edit Payment Method"
+	}
+
+	d := dialog.NewCustomConfirm(title, "Save", "Cancel", form, func(ok bool) {
+		if !ok {
+			return
+		}
+
+		editing.Label = labelEntry.Text
+		editing.Type = models.PaymentMethodType(typeSelect.Selected)
+		editing.LastFour = lastFourEntry.Text
+		editing.ExpiryMonth = time.Time{}
+		if text := strings.TrimSpace(expiryEntry.Text); text != "" {
+			expiry, err := time.ParseInLocation("01/2006", text, time.Local)
+			if err != nil {
+				dialog.ShowError(fmt.Errorf("expiry must be MM/YYYY, e.g. 03/2027"), p.app.window)
+				return
+			}
+			editing.ExpiryMonth = expiry
+		}
+
+		var err error
+		if method == nil {
+			err = p.app.methodService.Create(&editing)
+		} else {
+			err = p.app.methodService.Update(&editing)
+		}
+		if err != nil {
+			dialog.ShowError(err, p.app.window)
+			return
+		}
+
+		p.app.filterView.RefreshPaymentMethods()
+		p.app.Refresh()
+		p.refresh()
+	}, p.app.window)
+	d.Resize(fyne.NewSize(380, 0))
+	d.Show()
+}
+
+func (p *PaymentMethodsView) confirmDelete(method models.PaymentMethod) {
+	confirm := dialog.NewConfirm(
+		"Delete Payment Method",
+		fmt.Sprintf("Delete '%s'? Subscriptions billed to it will have no payment method.", method.Label),
+		func(ok bool) {
+			if !ok {
+				return
+			}
+			if err := p.app.methodService.Delete(method.ID); err != nil {
+				dialog.ShowError(err, p.app.window)
+				return
+			}
+			p.app.filterView.RefreshPaymentMethods()
+			p.app.Refresh()
+			p.refresh()
+		},
+		p.app.window,
+	)
+	confirm.Show()
+}
+
+// paymentMethodOptions returns the select options for the methods, "None" first
+func paymentMethodOptions(methods []models.PaymentMethod) []string {
+	options := []string{noPaymentMethod}
+	for _, method := range methods {
+		options = append(options, method.String())
+	}
+	return options
+}
+
+// paymentMethodLabel returns the select option for a method ID
+func paymentMethodLabel(methods []models.PaymentMethod, id string) string {
+	for _, method := range methods {
+		if method.ID == id {
+			return method.String()
+		}
+	}
+	return noPaymentMethod
+}
+
+// paymentMethodIDForLabel maps a select option back to its method ID
+func paymentMethodIDForLabel(methods []models.PaymentMethod, label string) string {
+	for _, method := range methods {
+		if method.String() == label {
+			return method.ID
+		}
+	}
+	return ""
+}
@@ -1,11 +1,18 @@
 package ui
 
 import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/container"
 	"fyne.io/fyne/v2/dialog"
 	"fyne.io/fyne/v2/theme"
 	"fyne.io/fyne/v2/widget"
+
+	"subman/internal/models"
 )
 
 type SettingsView struct {
@@ -28,13 +35,181 @@ func (s *SettingsView) Show() {
 	})
 	themeRadio.Selected = selectedTheme
 
+	// Base currency used for dashboard totals
+	baseCurrency := models.DefaultCurrency
+	if rates, err := s.app.service.GetExchangeRates(); err == nil {
+		baseCurrency = rates.BaseCurrency()
+	}
+	var currencySelect *widget.Select
+	currencySelect = widget.NewSelect(models.Currencies, func(value string) {
+		if value == baseCurrency {
+			return
+		}
+		if err := s.app.service.SetBaseCurrency(value); err != nil {
+			dialog.ShowError(err, s.app.window)
+			currencySelect.SetSelected(baseCurrency)
+			return
+		}
+		baseCurrency = value
+		s.app.Refresh()
+	})
+	currencySelect.Selected = baseCurrency
+
+	ratesBtn := widget.NewButton("Edit Exchange Rates", s.showExchangeRates)
+
+	// First month of the fiscal year used for Year to Date and yearly reports
+	fiscalYearStart, _ := s.app.service.GetFiscalYearStart()
+	var fiscalSelect *widget.Select
+	fiscalSelect = widget.NewSelect(monthNames(), func(value string) {
+		month := monthFromName(value)
+		if month == fiscalYearStart {
+			return
+		}
+		if err := s.app.service.SetFiscalYearStart(month); err != nil {
+			dialog.ShowError(err, s.app.window)
+			fiscalSelect.SetSelected(fiscalYearStart.String())
+			return
+		}
+		fiscalYearStart = month
+		s.app.Refresh()
+	})
+	fiscalSelect.Selected = fiscalYearStart.String()
+
+	categoriesBtn := widget.NewButton("Manage Categories", func() {
+		NewCategoriesView(s.app).Show()
+	})
+
+	methodsBtn := widget.NewButton("Manage Payment Methods", func() {
+		NewPaymentMethodsView(s.app).Show()
+	})
+
+	budgetsBtn := widget.NewButton("Manage Budgets", func() {
+		NewBudgetsView(s.app).Show()
+	})
+
+	// How long deleted subscriptions stay in the trash
+	purgeDays := s.app.fyneApp.Preferences().IntWithFallback(autoPurgePreference, 0)
+	purgeSelect := widget.NewSelect(autoPurgeOptions, func(value string) {
+		days := 0
+		if value != "Never" {
+			fmt.Sscanf(value, "%d", &days)
+		}
+		s.app.fyneApp.Preferences().SetInt(autoPurgePreference, days)
+	})
+	purgeSelect.Selected = "Never"
+	if purgeDays > 0 {
+		purgeSelect.Selected = fmt.Sprintf("%d days", purgeDays)
+	}
+
+	// Passphrase encryption of the data file
+	encryptionLabel := widget.NewLabel("")
+	encryptBtn := widget.NewButton("", nil)
+	turnOffBtn := widget.NewButton("Turn Off Encryption", nil)
+	refreshEncryption := func() {
+		encrypted, _ := s.app.service.IsEncrypted()
+		if encrypted {
+			encryptionLabel.SetText("Encryption: On")
+			encryptBtn.SetText("Change Passphrase")
+			turnOffBtn.Show()
+		} else {
+			encryptionLabel.SetText("Encryption: Off")
+			encryptBtn.SetText("Encrypt Data")
+			turnOffBtn.Hide()
+		}
+	}
+	encryptBtn.OnTapped = func() {
+		NewEncryptionView(s.app).ShowSetPassphrase(refreshEncryption)
+	}
+	turnOffBtn.OnTapped = func() {
+		NewEncryptionView(s.app).ShowTurnOff(refreshEncryption)
+	}
+	refreshEncryption()
+
 	content := container.NewVBox(
 		widget.NewLabel("Theme:"),
 		themeRadio,
+		widget.NewSeparator(),
+		widget.NewLabel("Base Currency:"),
+		currencySelect,
+		ratesBtn,
+		widget.NewSeparator(),
+		widget.NewLabel("Fiscal Year Starts In:"),
+		fiscalSelect,
+		widget.NewSeparator(),
+		widget.NewLabel("Categories:"),
+		categoriesBtn,
+		widget.NewLabel("Payment Methods:"),
+		methodsBtn,
+		widget.NewLabel("Budgets:"),
+		budgetsBtn,
+		widget.NewSeparator(),
+		widget.NewLabel("Empty Trash Automatically After:"),
+		purgeSelect,
+		widget.NewSeparator(),
+		encryptionLabel,
+		encryptBtn,
+		turnOffBtn,
 	)
 
-	d := dialog.NewCustom("Settings", "Close", content, s.app.window)
-	d.Resize(fyne.NewSize(300, 200))
+	d := dialog.NewCustom("Settings", "Close", container.NewVScroll(content), s.app.window)
+	d.Resize(fyne.NewSize(300, 700))
+	d.Show()
+}
+
+// showExchangeRates lets the user maintain the offline exchange-rate table
+func (s *SettingsView) showExchangeRates() {
+	rates, err := s.app.service.GetExchangeRates()
+	if err != nil {
+		dialog.ShowError(err, s.app.window)
+		return
+	}
+
+	base := rates.BaseCurrency()
+	entries := make(map[string]*widget.Entry)
+	var items []*widget.FormItem
+	for _, code := range models.Currencies {
+		if code == base {
+			continue
+		}
+		entry := widget.NewEntry()
+		entry.SetPlaceHolder("not set")
+		if rate, ok := rates.Rates[code]; ok && rate > 0 {
+			entry.SetText(strconv.FormatFloat(rate, 'f', -1, 64))
+		}
+		entries[code] = entry
+		items = append(items, widget.NewFormItem(fmt.Sprintf("1 %s =", code), container.NewBorder(nil, nil, nil, widget.NewLabel(base), entry)))
+	}
+
+	form := widget.NewForm(items...)
+	scroll := container.NewVScroll(form)
+	scroll.SetMinSize(fyne.NewSize(320, 400))
+
+	d := dialog.NewCustomConfirm("Exchange Rates", "Save", "Cancel", scroll, func(ok bool) {
+		if !ok {
+			return
+		}
+
+		updated := map[string]float64{}
+		for code, entry := range entries {
+			text := strings.TrimSpace(entry.Text)
+			if text == "" {
+				continue
+			}
+			rate, err := strconv.ParseFloat(text, 64)
+			if err != nil || rate <= 0 {
+				dialog.ShowError(fmt.Errorf("invalid rate for %s: %q", code, text), s.app.window)
+				return
+			}
+			updated[code] = rate
+		}
+
+		rates.Rates = updated
+		if err := s.app.service.SaveExchangeRates(rates); err != nil {
+			dialog.ShowError(err, s.app.window)
+			return
+		}
+		s.app.Refresh()
+	}, s.app.window)
 	d.Show()
 }
 
@@ -56,3 +231,22 @@ func (s *SettingsView) applyTheme(themeName string) {
 	// Refresh the UI to apply the new theme
 	s.app.Refresh()
 }
+
+// monthNames returns the month names from January to December
+func monthNames() []string {
+	names := make([]string, 12)
+	for i := range names {
+		names[i] = time.Month(i + 1).String()
+	}
+	return names
+}
+
+// monthFromName maps a month name back to its month, January if unknown
+func monthFromName(name string) time.Month {
+	for month := time.January; month <= time.December; month++ {
+		if month.String() == name {
+			return month
+		}
+	}
+	return time.January
+}
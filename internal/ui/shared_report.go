@@ -0,0 +1,67 @@
+package ui
+
+import (
+	"fmt"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"subman/internal/models"
+)
+
+// sharedReportMonths is how far back the shared costs report goes
+const sharedReportMonths = 12
+
+// SharedReportView shows who owes whom each month for shared subscriptions
+type SharedReportView struct {
+	app *App
+}
+
+func NewSharedReportView(app *App) *SharedReportView {
+	return &SharedReportView{
+		app: app,
+	}
+}
+
+func (r *SharedReportView) Show() {
+	now := time.Now()
+	from := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location()).AddDate(0, 1-sharedReportMonths, 0)
+
+	settlements, err := r.app.service.GetSettlements(from, now)
+	if err != nil {
+		dialog.ShowError(err, r.app.window)
+		return
+	}
+
+	content := container.NewVBox()
+	if len(settlements) == 0 {
+		content.Add(widget.NewLabel(fmt.Sprintf("No shared payments in the last %d months.", sharedReportMonths)))
+	}
+
+	// Newest month first
+	for i := len(settlements) - 1; i >= 0; i-- {
+		settlement := settlements[i]
+
+		monthLabel := widget.NewLabel(settlement.Month.Format("January 2006"))
+		monthLabel.TextStyle = fyne.TextStyle{Bold: true}
+		content.Add(monthLabel)
+
+		for _, debt := range settlement.Debts {
+			verb := "owes"
+			if debt.From == models.Me {
+				verb = "owe"
+			}
+			content.Add(widget.NewLabel(fmt.Sprintf("%s %s %s %s", debt.From, verb, debt.To, debt.Amount)))
+		}
+		content.Add(widget.NewSeparator())
+	}
+
+	scroll := container.NewVScroll(content)
+	scroll.SetMinSize(fyne.NewSize(400, 400))
+
+	d := dialog.NewCustom("Shared Costs", "Close", scroll, r.app.window)
+	d.Show()
+}
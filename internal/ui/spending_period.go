@@ -0,0 +1,225 @@
+package ui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"subman/internal/models"
+	"subman/pkg/calculator"
+)
+
+const customPeriod = "Custom Range..."
+
+// topSpendingSubscriptions is how many subscriptions the period breakdown names
+const topSpendingSubscriptions = 3
+
+// spendingPeriod is a dashboard period option and how to compute its range
+// Yearly periods follow the fiscal year start setting
+type spendingPeriod struct {
+	label   string
+	rangeAt func(now time.Time, fiscalYearStart time.Month) calculator.DateRange
+}
+
+var spendingPeriods = []spendingPeriod{
+	{"Year to Date", calculator.FiscalYearToDate},
+	{"Last Year", calculator.LastFiscalYear},
+	{"This Month", func(now time.Time, _ time.Month) calculator.DateRange { return calculator.MonthToDate(now) }},
+	{"Last Month", func(now time.Time, _ time.Month) calculator.DateRange { return calculator.LastMonth(now) }},
+	{"This Quarter", func(now time.Time, _ time.Month) calculator.DateRange { return calculator.QuarterToDate(now) }},
+	{"Last Quarter", func(now time.Time, _ time.Month) calculator.DateRange { return calculator.LastQuarter(now) }},
+	{"Last 30 Days", func(now time.Time, _ time.Month) calculator.DateRange { return calculator.LastDays(now, 30) }},
+	{"Last 12 Months", func(now time.Time, _ time.Month) calculator.DateRange {
+		return calculator.DaysRange(now.AddDate(-1, 0, 1), now)
+	}},
+}
+
+// SpendingPeriodView shows what was actually paid in a chosen period
+type SpendingPeriodView struct {
+	app          *App
+	periodSelect *widget.Select
+	totalLabel   *widget.Label
+	detailLabel  *widget.Label
+	period       string
+	custom       calculator.DateRange
+}
+
+func NewSpendingPeriodView(app *App) *SpendingPeriodView {
+	now := time.Now()
+	return &SpendingPeriodView{
+		app:         app,
+		totalLabel:  widget.NewLabel(""),
+		detailLabel: widget.NewLabel(""),
+		period:      spendingPeriods[0].label,
+		custom:      calculator.DaysRange(now.AddDate(0, -1, 0), now),
+	}
+}
+
+func (s *SpendingPeriodView) Render() fyne.CanvasObject {
+	options := make([]string, 0, len(spendingPeriods)+1)
+	for _, period := range spendingPeriods {
+		options = append(options, period.label)
+	}
+	options = append(options, customPeriod)
+
+	s.periodSelect = widget.NewSelect(options, func(selected string) {
+		if selected == customPeriod {
+			s.showCustomDialog()
+			return
+		}
+		s.period = selected
+		s.Refresh()
+	})
+	s.periodSelect.Selected = s.period
+
+	s.totalLabel.TextStyle = fyne.TextStyle{Bold: true}
+	s.detailLabel.Wrapping = fyne.TextWrapWord
+
+	s.Refresh()
+
+	return container.NewVBox(
+		container.NewHBox(widget.NewLabel("Spent in:"), s.periodSelect, s.totalLabel),
+		s.detailLabel,
+	)
+}
+
+// currentRange returns the date range of the selected period
+func (s *SpendingPeriodView) currentRange() calculator.DateRange {
+	fiscalYearStart, _ := s.app.service.GetFiscalYearStart()
+	for _, period := range spendingPeriods {
+		if period.label == s.period {
+			return period.rangeAt(time.Now(), fiscalYearStart)
+		}
+	}
+	return s.custom
+}
+
+func (s *SpendingPeriodView) Refresh() {
+	summary, err := s.app.paymentService.GetSpendingSummary(s.currentRange())
+	if err != nil {
+		s.totalLabel.SetText("Error")
+		s.detailLabel.Hide()
+		return
+	}
+
+	s.totalLabel.SetText(fmt.Sprintf("%s across %d payments (%s)", summary.Total, summary.PaymentCount, summary.Range))
+	if summary.PaymentCount == 0 {
+		s.detailLabel.Hide()
+		return
+	}
+
+	var lines []string
+	if categories, err := s.app.categoryService.List(); err == nil {
+		lines = append(lines, "By category - "+formatCategoryTotals(categories, summary.ByCategory))
+	}
+	if list, err := s.app.service.GetStorage().Load(); err == nil {
+		lines = append(lines, "Top subscriptions - "+formatTopSubscriptions(list.Subscriptions, summary.BySubscription))
+	}
+	s.detailLabel.SetText(strings.Join(lines, "\n"))
+	s.detailLabel.Show()
+}
+
+// showCustomDialog asks for the days of a custom period
+func (s *SpendingPeriodView) showCustomDialog() {
+	fromEntry := widget.NewEntry()
+	fromEntry.SetPlaceHolder("YYYY-MM-DD")
+	fromEntry.SetText(s.custom.Start.Format("2006-01-02"))
+
+	toEntry := widget.NewEntry()
+	toEntry.SetPlaceHolder("YYYY-MM-DD")
+	toEntry.SetText(s.custom.End.AddDate(0, 0, -1).Format("2006-01-02"))
+
+	form := widget.NewForm(
+		widget.NewFormItem("From", fromEntry),
+		widget.NewFormItem("To", toEntry),
+	)
+
+	d := dialog.NewCustomConfirm("Custom Range", "Apply", "Cancel", form, func(ok bool) {
+		if !ok {
+			s.periodSelect.SetSelected(s.period)
+			return
+		}
+
+		from, err := time.ParseInLocation("2006-01-02", strings.TrimSpace(fromEntry.Text), time.Local)
+		if err != nil {
+			dialog.ShowError(fmt.Errorf("from date must be YYYY-MM-DD"), s.app.window)
+			s.periodSelect.SetSelected(s.period)
+			return
+		}
+		to, err := time.ParseInLocation("2006-01-02", strings.TrimSpace(toEntry.Text), time.Local)
+		if err != nil {
+			dialog.ShowError(fmt.Errorf("to date must be YYYY-MM-DD"), s.app.window)
+			s.periodSelect.SetSelected(s.period)
+			return
+		}
+		if to.Before(from) {
+			dialog.ShowError(fmt.Errorf("the range must end on or after its start"), s.app.window)
+			s.periodSelect.SetSelected(s.period)
+			return
+		}
+
+		s.custom = calculator.DaysRange(from, to)
+		s.period = customPeriod
+		s.Refresh()
+	}, s.app.window)
+	d.Resize(fyne.NewSize(320, 0))
+	d.Show()
+}
+
+// formatCategoryTotals lists category totals, largest first
+func formatCategoryTotals(categories []models.CategoryDef, totals map[models.Category]models.Money) string {
+	ids := make([]models.Category, 0, len(totals))
+	for id := range totals {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		if totals[ids[i]].Amount != totals[ids[j]].Amount {
+			return totals[ids[i]].Amount > totals[ids[j]].Amount
+		}
+		return ids[i] < ids[j]
+	})
+
+	parts := make([]string, len(ids))
+	for i, id := range ids {
+		parts[i] = fmt.Sprintf("%s: %s", categoryName(categories, id), totals[id])
+	}
+	return strings.Join(parts, "   ")
+}
+
+// formatTopSubscriptions names the subscriptions that cost the most
+func formatTopSubscriptions(subscriptions []models.Subscription, totals map[string]models.Money) string {
+	names := make(map[string]string)
+	for _, sub := range subscriptions {
+		names[sub.ID] = sub.Name
+	}
+
+	ids := make([]string, 0, len(totals))
+	for id := range totals {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		if totals[ids[i]].Amount != totals[ids[j]].Amount {
+			return totals[ids[i]].Amount > totals[ids[j]].Amount
+		}
+		return ids[i] < ids[j]
+	})
+	if len(ids) > topSpendingSubscriptions {
+		ids = ids[:topSpendingSubscriptions]
+	}
+
+	parts := make([]string, len(ids))
+	for i, id := range ids {
+		name, ok := names[id]
+		if !ok {
+			name = "(removed)"
+		}
+		parts[i] = fmt.Sprintf("%s: %s", name, totals[id])
+	}
+	return strings.Join(parts, "   ")
+}
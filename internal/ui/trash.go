@@ -0,0 +1,137 @@
+package ui
+
+import (
+	"fmt"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"subman/internal/models"
+)
+
+// autoPurgePreference is the number of days deleted subscriptions stay in the
+// trash before they are purged automatically; 0 keeps them forever
+const autoPurgePreference = "trash_auto_purge_days"
+
+// autoPurgeOptions are the retention periods offered in settings
+var autoPurgeOptions = []string{"Never", "7 days", "30 days", "90 days", "365 days"}
+
+type TrashView struct {
+	app           *App
+	listContainer *fyne.Container
+}
+
+func NewTrashView(app *App) *TrashView {
+	return &TrashView{
+		app: app,
+	}
+}
+
+func (t *TrashView) Show() {
+	t.listContainer = container.NewVBox()
+	t.refresh()
+
+	emptyBtn := widget.NewButton("Empty Trash", t.confirmEmpty)
+
+	scroll := container.NewVScroll(t.listContainer)
+	scroll.SetMinSize(fyne.NewSize(520, 360))
+
+	content := container.NewBorder(nil, emptyBtn, nil, nil, scroll)
+
+	d := dialog.NewCustom("Trash", "Close", content, t.app.window)
+	d.Show()
+}
+
+func (t *TrashView) refresh() {
+	deleted, err := t.app.service.ListDeleted()
+	if err != nil {
+		dialog.ShowError(err, t.app.window)
+		return
+	}
+
+	t.listContainer.Objects = nil
+	if len(deleted) == 0 {
+		t.listContainer.Add(widget.NewLabel("Trash is empty."))
+	}
+
+	for _, sub := range deleted {
+		sub := sub
+		info := widget.NewLabel(fmt.Sprintf("%s\n%s %s - deleted %s",
+			sub.Name, sub.BilledCost().String(), sub.CycleLabel(), sub.DeletedAt.Format("Jan 2, 2006")))
+
+		restoreBtn := widget.NewButton("Restore", func() {
+			if err := t.app.service.Restore(sub.ID); err != nil {
+				dialog.ShowError(err, t.app.window)
+				return
+			}
+			t.app.Refresh()
+			t.refresh()
+		})
+		purgeBtn := widget.NewButton("Delete Forever", func() {
+			t.confirmPurge(sub)
+		})
+
+		t.listContainer.Add(container.NewBorder(nil, nil, nil, container.NewHBox(restoreBtn, purgeBtn), info))
+	}
+
+	t.listContainer.Refresh()
+}
+
+// confirmPurge permanently deletes one subscription, optionally with its payments
+func (t *TrashView) confirmPurge(sub models.Subscription) {
+	paymentsCheck := widget.NewCheck("Also delete its payment history", nil)
+
+	content := container.NewVBox(
+		widget.NewLabel(fmt.Sprintf("Permanently delete '%s'? This cannot be undone.", sub.Name)),
+		paymentsCheck,
+	)
+
+	confirm := dialog.NewCustomConfirm("Delete Forever", "Delete", "Cancel", content, func(ok bool) {
+		if !ok {
+			return
+		}
+		if err := t.app.service.Purge(sub.ID, paymentsCheck.Checked); err != nil {
+			dialog.ShowError(err, t.app.window)
+			return
+		}
+		t.app.Refresh()
+		t.refresh()
+	}, t.app.window)
+	confirm.Show()
+}
+
+// confirmEmpty permanently deletes everything in the trash, payments included
+func (t *TrashView) confirmEmpty() {
+	confirm := dialog.NewConfirm(
+		"Empty Trash",
+		"Permanently delete every subscription in the trash, along with its payment history?",
+		func(ok bool) {
+			if !ok {
+				return
+			}
+			if _, err := t.app.service.PurgeDeletedBefore(time.Now()); err != nil {
+				dialog.ShowError(err, t.app.window)
+				return
+			}
+			t.app.Refresh()
+			t.refresh()
+		},
+		t.app.window,
+	)
+	confirm.Show()
+}
+
+// autoPurgeTrash permanently removes subscriptions that have been in the trash
+// longer than the configured retention period
+func (a *App) autoPurgeTrash() error {
+	days := a.fyneApp.Preferences().IntWithFallback(autoPurgePreference, 0)
+	if days <= 0 {
+		return nil
+	}
+
+	_, err := a.service.PurgeDeletedBefore(time.Now().AddDate(0, 0, -days))
+	return err
+}
@@ -1,7 +1,11 @@
 package main
 
 import (
+	"errors"
+	"flag"
+	"fmt"
 	"log"
+	"os"
 
 	"subman/internal/service"
 	"subman/internal/storage"
@@ -9,17 +13,66 @@ import (
 )
 
 func main() {
+	// Storage backend: -storage flag, else SUBMAN_STORAGE, else JSON
+	backend := os.Getenv("SUBMAN_STORAGE")
+	if backend == "" {
+		backend = "json"
+	}
+	flag.StringVar(&backend, "storage", backend, "storage backend: json or sqlite")
+	flag.Parse()
+
 	// Initialize storage
-	store, err := storage.NewJSONStorage()
+	store, err := openStorage(backend)
 	if err != nil {
 		log.Fatalf("Failed to initialize storage: %v", err)
 	}
 
+	// Exchange rates live next to the subscriptions file
+	rates := storage.NewRatesStorage(store.GetPath())
+
 	// Initialize services
-	svc := service.NewSubscriptionService(store)
-	paymentSvc := service.NewPaymentService(store)
+	svc := service.NewSubscriptionService(store, rates)
+	paymentSvc := service.NewPaymentService(store, rates)
+	categorySvc := service.NewCategoryService(store)
+	methodSvc := service.NewPaymentMethodService(store)
+	budgetSvc := service.NewBudgetService(store, rates)
 
 	// Create and run UI
-	app := ui.NewApp(svc, paymentSvc)
+	app := ui.NewApp(svc, paymentSvc, categorySvc, methodSvc, budgetSvc)
 	app.Run()
 }
+
+// openStorage opens the chosen backend; the first time SQLite is used the
+// existing JSON data file is migrated into the database
+func openStorage(backend string) (storage.Storage, error) {
+	jsonStore, err := storage.NewJSONStorage()
+	if err != nil {
+		return nil, err
+	}
+
+	switch backend {
+	case "json":
+		return jsonStore, nil
+	case "sqlite":
+		db, err := storage.NewSQLiteStorage()
+		if err != nil {
+			return nil, err
+		}
+		migrated, err := storage.MigrateJSONToSQLite(jsonStore.GetPath(), db)
+		if errors.Is(err, storage.ErrEncryptedMigration) {
+			// Keep the data encrypted rather than refuse to start
+			db.Close()
+			log.Printf("Not using SQLite storage: %v", err)
+			return jsonStore, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to migrate %s: %w", jsonStore.GetPath(), err)
+		}
+		if migrated {
+			log.Printf("Migrated %s to %s", jsonStore.GetPath(), db.GetPath())
+		}
+		return db, nil
+	default:
+		return nil, fmt.Errorf("unknown storage backend %q (use json or sqlite)", backend)
+	}
+}
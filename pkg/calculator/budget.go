@@ -0,0 +1,91 @@
+package calculator
+
+import (
+	"time"
+
+	"subman/internal/models"
+)
+
+// BudgetNearPercent is the utilization at which a budget is close to its limit
+const BudgetNearPercent = 90
+
+// BudgetStatus is how much of a monthly budget is used
+// Planned is the monthly cost of the active subscriptions in scope, as in
+// CostSummary.TotalMonthly; Spent is what was actually paid this month.
+// Amounts are in the base currency of the exchange-rate table.
+type BudgetStatus struct {
+	Budget         models.Budget
+	Limit          models.Money
+	Planned        models.Money
+	Spent          models.Money
+	PlannedPercent float64
+	SpentPercent   float64
+}
+
+// Over reports whether planned or actual spending exceeds the limit
+func (s BudgetStatus) Over() bool {
+	return s.Planned.Amount > s.Limit.Amount || s.Spent.Amount > s.Limit.Amount
+}
+
+// Near reports whether planned or actual spending is close to the limit
+func (s BudgetStatus) Near() bool {
+	return s.PlannedPercent >= BudgetNearPercent || s.SpentPercent >= BudgetNearPercent
+}
+
+// CalculateBudgets works out the utilization of each budget against the
+// monthly totals of summary and the payments made in now's month so far
+func CalculateBudgets(budgets []models.Budget, summary *models.CostSummary, subscriptions []models.Subscription, payments []models.Payment, rates *models.ExchangeRates, now time.Time) []BudgetStatus {
+	base := rates.BaseCurrency()
+
+	subs := make(map[string]models.Subscription)
+	for _, sub := range subscriptions {
+		subs[sub.ID] = sub
+	}
+
+	month := MonthToDate(now)
+	statuses := make([]BudgetStatus, 0, len(budgets))
+	for _, budget := range budgets {
+		limit, _ := rates.ToBase(budget.Limit)
+		status := BudgetStatus{
+			Budget:  budget,
+			Limit:   limit,
+			Planned: models.Zero(base),
+			Spent:   models.Zero(base),
+		}
+
+		switch budget.Scope {
+		case models.BudgetCategory:
+			status.Planned = status.Planned.Add(summary.ByCategory[models.Category(budget.Target)])
+		case models.BudgetTag:
+			status.Planned = status.Planned.Add(summary.ByTag[models.NormalizeTag(budget.Target)])
+		default:
+			status.Planned = summary.TotalMonthly
+		}
+
+		for _, payment := range payments {
+			if !payment.IsSettled() || !month.Contains(payment.PaymentDate) {
+				continue
+			}
+			sub, ok := subs[payment.SubscriptionID]
+			if budget.Scope != models.BudgetOverall && (!ok || !budget.Applies(sub)) {
+				continue
+			}
+			amount, _ := rates.ToBase(payment.Spent())
+			status.Spent = status.Spent.Add(amount)
+		}
+
+		status.PlannedPercent = percentOf(status.Planned, limit)
+		status.SpentPercent = percentOf(status.Spent, limit)
+		statuses = append(statuses, status)
+	}
+
+	return statuses
+}
+
+// percentOf returns amount as a percentage of limit (0 for an empty limit)
+func percentOf(amount, limit models.Money) float64 {
+	if limit.Amount <= 0 {
+		return 0
+	}
+	return float64(amount.Amount) * 100 / float64(limit.Amount)
+}
@@ -1,20 +1,49 @@
 package calculator
 
 import (
+	"sort"
 	"time"
 
 	"subman/internal/models"
 )
 
+// TrialEndingSoonDays is how far ahead a trial conversion counts as upcoming
+const TrialEndingSoonDays = 7
+
 // CalculateSummary computes cost statistics from subscriptions and payments
-// Paused and deleted subscriptions are counted separately and excluded from cost totals
-// YTD is calculated from actual payment records
-func CalculateSummary(subscriptions []models.Subscription, payments []models.Payment) *models.CostSummary {
+// Paused, cancelled and deleted subscriptions are counted separately and excluded from cost totals
+// YTD is calculated from actual payment records since the start of the fiscal
+// year, which begins on the first of fiscalYearStart
+// Subscriptions in a free trial cost nothing yet and are counted separately
+// Gross totals include other people's shares; MyMonthly is only my part
+// Amounts are converted to the base currency of rates; unknown currencies count 1:1
+func CalculateSummary(subscriptions []models.Subscription, payments []models.Payment, rates *models.ExchangeRates, fiscalYearStart time.Month) *models.CostSummary {
+	now := time.Now()
+	trialSoon := now.AddDate(0, 0, TrialEndingSoonDays)
+	missing := make(map[string]bool)
+
+	toBase := func(amount models.Money) models.Money {
+		converted, ok := rates.ToBase(amount)
+		if !ok {
+			missing[amount.CurrencyCode()] = true
+		}
+		return converted
+	}
+
+	base := rates.BaseCurrency()
 	summary := &models.CostSummary{
-		ByCategory:  make(map[models.Category]float64),
-		Count:       0,
-		PausedCount: 0,
-		YearToDate:  0,
+		Currency:     base,
+		TotalMonthly: models.Zero(base),
+		TotalYearly:  models.Zero(base),
+		MyMonthly:    models.Zero(base),
+		TrialMonthly: models.Zero(base),
+		SavedMonthly: models.Zero(base),
+		SavedTotal:   models.Zero(base),
+		ByCategory:   make(map[models.Category]models.Money),
+		ByTag:        make(map[string]models.Money),
+		Count:        0,
+		PausedCount:  0,
+		YearToDate:   models.Zero(base),
 	}
 
 	for _, sub := range subscriptions {
@@ -23,61 +52,127 @@ func CalculateSummary(subscriptions []models.Subscription, payments []models.Pay
 			continue
 		}
 
+		// Cancelled subscriptions no longer cost anything; report what they save
+		if sub.IsCancelled() {
+			summary.CancelledCount++
+			price := sub.PriceAt(sub.CancelledAt)
+			summary.SavedMonthly = summary.SavedMonthly.Add(toBase(ToMonthlyCost(price, sub.Interval())))
+			summary.SavedTotal = summary.SavedTotal.Add(toBase(AvoidedCharges(sub, now)))
+			continue
+		}
+
 		if sub.Paused {
 			summary.PausedCount++
 			continue
 		}
 
+		if sub.InTrial(now) {
+			summary.TrialCount++
+			if sub.TrialEndDate.Before(trialSoon) {
+				summary.TrialsEnding++
+			}
+			summary.TrialMonthly = summary.TrialMonthly.Add(toBase(ToMonthlyCost(sub.PriceAt(sub.TrialEndDate), sub.Interval())))
+			continue
+		}
+
 		summary.Count++
-		monthlyCost := ToMonthlyCost(sub.Cost, sub.BillingCycle)
-		summary.TotalMonthly += monthlyCost
-		summary.ByCategory[sub.Category] += monthlyCost
+		price := sub.PriceAt(now)
+		monthlyCost := toBase(ToMonthlyCost(price, sub.Interval()))
+		summary.TotalMonthly = summary.TotalMonthly.Add(monthlyCost)
+		summary.MyMonthly = summary.MyMonthly.Add(toBase(ToMonthlyCost(sub.MyShare(price), sub.Interval())))
+		if sub.IsShared() {
+			summary.SharedCount++
+		}
+		summary.TotalYearly = summary.TotalYearly.Add(toBase(ProjectedYearlyCost(sub, now)))
+		summary.ByCategory[sub.Category] = summary.ByCategory[sub.Category].Add(monthlyCost)
+		for _, tag := range models.NormalizeTags(sub.Tags) {
+			summary.ByTag[tag] = summary.ByTag[tag].Add(monthlyCost)
+		}
 	}
 
-	summary.TotalYearly = summary.TotalMonthly * 12
-
-	// Calculate YTD from actual payments (Jan 1 to today)
-	now := time.Now()
-	startOfYear := time.Date(now.Year(), 1, 1, 0, 0, 0, 0, now.Location())
-
+	// Calculate YTD from actual payments (start of the fiscal year to today)
+	// Refunds and credits reduce it; failed and pending payments don't count
+	ytd := FiscalYearToDate(now, fiscalYearStart)
+	summary.YearStart = ytd.Start
 	for _, payment := range payments {
-		if payment.PaymentDate.After(startOfYear) || payment.PaymentDate.Equal(startOfYear) {
-			if payment.PaymentDate.Before(now) || payment.PaymentDate.Equal(now.Truncate(24*time.Hour)) {
-				summary.YearToDate += payment.Amount
-			}
+		if payment.IsSettled() && ytd.Contains(payment.PaymentDate) {
+			summary.YearToDate = summary.YearToDate.Add(toBase(payment.Spent()))
 		}
 	}
 
+	for currency := range missing {
+		summary.MissingRates = append(summary.MissingRates, currency)
+	}
+	sort.Strings(summary.MissingRates)
+
 	return summary
 }
 
 // ToMonthlyCost converts any cost to monthly equivalent
-func ToMonthlyCost(cost float64, cycle models.BillingCycle) float64 {
-	if cycle == models.Yearly {
-		return cost / 12
-	}
-	return cost
+// The exact value cost * perYear / 12 is rounded once, half-to-even, to the minor unit
+func ToMonthlyCost(cost models.Money, interval models.BillingInterval) models.Money {
+	num, den := interval.PerYear()
+	return cost.MulDiv(num, den*12)
 }
 
 // ToYearlyCost converts any cost to yearly equivalent
-func ToYearlyCost(cost float64, cycle models.BillingCycle) float64 {
-	if cycle == models.Monthly {
-		return cost * 12
+func ToYearlyCost(cost models.Money, interval models.BillingInterval) models.Money {
+	num, den := interval.PerYear()
+	return cost.MulDiv(num, den)
+}
+
+// ProjectedYearlyCost sums the charges due in the 12 months from now at the
+// prices scheduled for their dates. Without scheduled price changes, or for
+// subscriptions billed less than once a year, it is the plain yearly equivalent.
+func ProjectedYearlyCost(sub models.Subscription, now time.Time) models.Money {
+	interval := sub.Interval()
+	current := sub.PriceAt(now)
+
+	num, den := interval.PerYear()
+	if _, scheduled := sub.NextPriceChange(now); !scheduled || num < den {
+		return ToYearlyCost(current, interval)
+	}
+
+	next := sub.NextPayment
+	if next.Before(now) {
+		next = CalculateNextPayment(sub.BillingStart(), interval)
 	}
-	return cost
+
+	end := now.AddDate(1, 0, 0)
+	total := models.Zero(current.Currency)
+	for date := next; date.Before(end); date = interval.Next(date) {
+		total = total.Add(sub.PriceAt(date))
+	}
+	return total
 }
 
-// CalculateNextPayment calculates the next payment date based on current date and cycle
-func CalculateNextPayment(lastPayment time.Time, cycle models.BillingCycle) time.Time {
+// AvoidedCharges sums the charges a cancelled subscription would have made
+// between its cancellation and now, at the price in force when it was cancelled
+func AvoidedCharges(sub models.Subscription, now time.Time) models.Money {
+	price := sub.PriceAt(sub.CancelledAt)
+	total := models.Zero(price.Currency)
+	if !sub.IsCancelled() {
+		return total
+	}
+
+	interval := sub.Interval()
+	date := sub.BillingStart()
+	for !date.After(sub.CancelledAt) {
+		date = interval.Next(date)
+	}
+	for ; date.Before(now); date = interval.Next(date) {
+		total = total.Add(price)
+	}
+	return total
+}
+
+// CalculateNextPayment calculates the next payment date based on current date and interval
+func CalculateNextPayment(lastPayment time.Time, interval models.BillingInterval) time.Time {
 	now := time.Now()
 	next := lastPayment
 
 	for next.Before(now) {
-		if cycle == models.Monthly {
-			next = next.AddDate(0, 1, 0)
-		} else {
-			next = next.AddDate(1, 0, 0)
-		}
+		next = interval.Next(next)
 	}
 
 	return next
@@ -0,0 +1,179 @@
+package calculator
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"subman/internal/models"
+)
+
+// DateRange is a span of days; Start is inclusive and End exclusive
+// The bounds are UTC midnights, like billing and payment dates.
+type DateRange struct {
+	Start time.Time
+	End   time.Time
+}
+
+// Contains reports whether the calendar day of t falls within the range
+func (r DateRange) Contains(t time.Time) bool {
+	day := models.CalendarDay(t)
+	return !day.Before(r.Start) && day.Before(r.End)
+}
+
+// Months returns the number of calendar months the range touches (at least 1)
+func (r DateRange) Months() int {
+	last := r.End.Add(-time.Nanosecond)
+	months := (last.Year()-r.Start.Year())*12 + int(last.Month()-r.Start.Month()) + 1
+	if months < 1 {
+		return 1
+	}
+	return months
+}
+
+// String formats the range as "Jan 2, 2006 - Mar 31, 2006"
+func (r DateRange) String() string {
+	return r.Start.Format("Jan 2, 2006") + " - " + r.End.AddDate(0, 0, -1).Format("Jan 2, 2006")
+}
+
+// startOfDay returns midnight UTC of t's calendar day
+func startOfDay(t time.Time) time.Time {
+	return models.CalendarDay(t)
+}
+
+// startOfMonth returns midnight UTC of the first of t's month
+func startOfMonth(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
+
+// DaysRange returns the range covering the days from through to, inclusive
+func DaysRange(from, to time.Time) DateRange {
+	return DateRange{Start: startOfDay(from), End: startOfDay(to).AddDate(0, 0, 1)}
+}
+
+// YearToDate returns Jan 1 of now's year through today (the calendar year)
+func YearToDate(now time.Time) DateRange {
+	return DaysRange(time.Date(now.Year(), 1, 1, 0, 0, 0, 0, time.UTC), now)
+}
+
+// MonthToDate returns the first of now's month through today
+func MonthToDate(now time.Time) DateRange {
+	return DaysRange(startOfMonth(now), now)
+}
+
+// LastMonth returns the whole calendar month before now's
+func LastMonth(now time.Time) DateRange {
+	start := startOfMonth(now).AddDate(0, -1, 0)
+	return DateRange{Start: start, End: start.AddDate(0, 1, 0)}
+}
+
+// QuarterToDate returns the start of now's calendar quarter through today
+func QuarterToDate(now time.Time) DateRange {
+	month := time.Month((int(now.Month())-1)/3*3 + 1)
+	return DaysRange(time.Date(now.Year(), month, 1, 0, 0, 0, 0, time.UTC), now)
+}
+
+// LastQuarter returns the whole calendar quarter before now's
+func LastQuarter(now time.Time) DateRange {
+	start := QuarterToDate(now).Start.AddDate(0, -3, 0)
+	return DateRange{Start: start, End: start.AddDate(0, 3, 0)}
+}
+
+// LastDays returns the last n days, including today
+func LastDays(now time.Time, n int) DateRange {
+	return DaysRange(now.AddDate(0, 0, 1-n), now)
+}
+
+// FiscalYearStart returns the first day of the fiscal year containing now's
+// calendar day, for a fiscal year starting on the first of startMonth (January
+// if out of range), as midnight UTC
+func FiscalYearStart(now time.Time, startMonth time.Month) time.Time {
+	if startMonth < time.January || startMonth > time.December {
+		startMonth = time.January
+	}
+	year := now.Year()
+	if now.Month() < startMonth {
+		year--
+	}
+	return time.Date(year, startMonth, 1, 0, 0, 0, 0, time.UTC)
+}
+
+// FiscalYearToDate returns the start of the fiscal year containing now through today
+func FiscalYearToDate(now time.Time, startMonth time.Month) DateRange {
+	return DaysRange(FiscalYearStart(now, startMonth), now)
+}
+
+// LastFiscalYear returns the whole fiscal year before the one containing now
+func LastFiscalYear(now time.Time, startMonth time.Month) DateRange {
+	end := FiscalYearStart(now, startMonth)
+	return DateRange{Start: end.AddDate(-1, 0, 0), End: end}
+}
+
+// FiscalYearLabel names the fiscal year starting at start, e.g. "2026" for a
+// calendar year or "FY 2026/27" for one starting later in the year
+func FiscalYearLabel(start time.Time) string {
+	if start.Month() == time.January {
+		return start.Format("2006")
+	}
+	return fmt.Sprintf("FY %d/%02d", start.Year(), (start.Year()+1)%100)
+}
+
+// SpendingSummary is what was actually paid in a date range, from payment records
+// Amounts are converted to the base currency of the exchange-rate table
+type SpendingSummary struct {
+	Range          DateRange
+	Currency       string
+	Total          models.Money
+	ByCategory     map[models.Category]models.Money
+	BySubscription map[string]models.Money // Keyed by subscription ID
+	PaymentCount   int                     // Settled payments (charges, refunds and credits) in the range
+	MissingRates   []string                // Currencies with no exchange rate (counted 1:1)
+}
+
+// SummarizeRange totals the settled payments made within the range
+// Refunds and credits reduce the totals; failed and pending payments are ignored.
+// Payments of subscriptions that no longer exist count towards the Other category.
+func SummarizeRange(subscriptions []models.Subscription, payments []models.Payment, rates *models.ExchangeRates, r DateRange) *SpendingSummary {
+	base := rates.BaseCurrency()
+	summary := &SpendingSummary{
+		Range:          r,
+		Currency:       base,
+		Total:          models.Zero(base),
+		ByCategory:     make(map[models.Category]models.Money),
+		BySubscription: make(map[string]models.Money),
+	}
+
+	categories := make(map[string]models.Category)
+	for _, sub := range subscriptions {
+		categories[sub.ID] = sub.Category
+	}
+
+	missing := make(map[string]bool)
+	for _, payment := range payments {
+		if !payment.IsSettled() || !r.Contains(payment.PaymentDate) {
+			continue
+		}
+
+		amount, ok := rates.ToBase(payment.Spent())
+		if !ok {
+			missing[payment.Amount.CurrencyCode()] = true
+		}
+
+		category, ok := categories[payment.SubscriptionID]
+		if !ok {
+			category = models.Other
+		}
+
+		summary.PaymentCount++
+		summary.Total = summary.Total.Add(amount)
+		summary.ByCategory[category] = summary.ByCategory[category].Add(amount)
+		summary.BySubscription[payment.SubscriptionID] = summary.BySubscription[payment.SubscriptionID].Add(amount)
+	}
+
+	for currency := range missing {
+		summary.MissingRates = append(summary.MissingRates, currency)
+	}
+	sort.Strings(summary.MissingRates)
+
+	return summary
+}
@@ -0,0 +1,128 @@
+package calculator
+
+import (
+	"testing"
+	"time"
+
+	"subman/internal/models"
+)
+
+var (
+	newYork = time.FixedZone("EDT", -4*60*60)
+	tokyo   = time.FixedZone("JST", 9*60*60)
+)
+
+// utcDay returns a billing date as the app stores it
+func utcDay(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+func TestDateRangeBoundaries(t *testing.T) {
+	tests := []struct {
+		name    string
+		r       DateRange
+		first   time.Time // First day in the range
+		last    time.Time // Last day in the range
+		outside []time.Time
+	}{
+		{
+			// Late evening in New York is already the next day in UTC
+			name:    "month to date, west of UTC",
+			r:       MonthToDate(time.Date(2026, 10, 31, 22, 0, 0, 0, newYork)),
+			first:   utcDay(2026, 10, 1),
+			last:    utcDay(2026, 10, 31),
+			outside: []time.Time{utcDay(2026, 9, 30), utcDay(2026, 11, 1)},
+		},
+		{
+			// Morning in Tokyo is still the day before in UTC
+			name:    "month to date, east of UTC",
+			r:       MonthToDate(time.Date(2026, 11, 1, 8, 0, 0, 0, tokyo)),
+			first:   utcDay(2026, 11, 1),
+			last:    utcDay(2026, 11, 1),
+			outside: []time.Time{utcDay(2026, 10, 31), utcDay(2026, 11, 2)},
+		},
+		{
+			name:    "last month, west of UTC",
+			r:       LastMonth(time.Date(2026, 11, 1, 0, 30, 0, 0, newYork)),
+			first:   utcDay(2026, 10, 1),
+			last:    utcDay(2026, 10, 31),
+			outside: []time.Time{utcDay(2026, 9, 30), utcDay(2026, 11, 1)},
+		},
+		{
+			name:    "year to date, west of UTC",
+			r:       YearToDate(time.Date(2026, 12, 31, 23, 0, 0, 0, newYork)),
+			first:   utcDay(2026, 1, 1),
+			last:    utcDay(2026, 12, 31),
+			outside: []time.Time{utcDay(2025, 12, 31), utcDay(2027, 1, 1)},
+		},
+		{
+			name:    "last quarter, east of UTC",
+			r:       LastQuarter(time.Date(2026, 10, 1, 1, 0, 0, 0, tokyo)),
+			first:   utcDay(2026, 7, 1),
+			last:    utcDay(2026, 9, 30),
+			outside: []time.Time{utcDay(2026, 6, 30), utcDay(2026, 10, 1)},
+		},
+	}
+
+	for _, tt := range tests {
+		if !tt.r.Contains(tt.first) || !tt.r.Contains(tt.last) {
+			t.Errorf("%s: %v doesn't contain %s and %s", tt.name, tt.r,
+				tt.first.Format("2006-01-02"), tt.last.Format("2006-01-02"))
+		}
+		for _, day := range tt.outside {
+			if tt.r.Contains(day) {
+				t.Errorf("%s: %v contains %s", tt.name, tt.r, day.Format("2006-01-02"))
+			}
+		}
+	}
+}
+
+func TestSummarizeRangeMonthEnds(t *testing.T) {
+	payments := []models.Payment{
+		{SubscriptionID: "a", Amount: models.NewMoney(100, "USD"), Type: models.PaymentCharged, PaymentDate: utcDay(2026, 9, 30)},
+		{SubscriptionID: "a", Amount: models.NewMoney(200, "USD"), Type: models.PaymentCharged, PaymentDate: utcDay(2026, 10, 1)},
+		{SubscriptionID: "a", Amount: models.NewMoney(400, "USD"), Type: models.PaymentCharged, PaymentDate: utcDay(2026, 10, 31)},
+		{SubscriptionID: "a", Amount: models.NewMoney(800, "USD"), Type: models.PaymentCharged, PaymentDate: utcDay(2026, 11, 1)},
+	}
+
+	// Just after midnight on Nov 1 in New York, last month is October
+	summary := SummarizeRange(nil, payments, nil, LastMonth(time.Date(2026, 11, 1, 0, 30, 0, 0, newYork)))
+	if summary.PaymentCount != 2 || summary.Total != models.NewMoney(600, "USD") {
+		t.Errorf("October: %d payments totalling %v, want 2 totalling 6.00 USD", summary.PaymentCount, summary.Total)
+	}
+}
+
+func TestFiscalYearBoundaries(t *testing.T) {
+	// A fiscal year starting in April, late on its last day in New York
+	now := time.Date(2027, 3, 31, 22, 0, 0, 0, newYork) // Already Apr 1 in UTC
+
+	start := FiscalYearStart(now, time.April)
+	if !start.Equal(utcDay(2026, 4, 1)) {
+		t.Errorf("fiscal year start = %v, want 2026-04-01", start)
+	}
+	if got := FiscalYearLabel(start); got != "FY 2026/27" {
+		t.Errorf("label = %q, want FY 2026/27", got)
+	}
+
+	ytd := FiscalYearToDate(now, time.April)
+	for _, day := range []time.Time{utcDay(2026, 4, 1), utcDay(2027, 3, 31)} {
+		if !ytd.Contains(day) {
+			t.Errorf("year to date %v doesn't contain %s", ytd, day.Format("2006-01-02"))
+		}
+	}
+	for _, day := range []time.Time{utcDay(2026, 3, 31), utcDay(2027, 4, 1)} {
+		if ytd.Contains(day) {
+			t.Errorf("year to date %v contains %s", ytd, day.Format("2006-01-02"))
+		}
+	}
+
+	// Just after midnight on the first day of the next fiscal year in Tokyo,
+	// still the day before in UTC
+	last := LastFiscalYear(time.Date(2027, 4, 1, 1, 0, 0, 0, tokyo), time.April)
+	if !last.Start.Equal(utcDay(2026, 4, 1)) || !last.End.Equal(utcDay(2027, 4, 1)) {
+		t.Errorf("last fiscal year = %v, want Apr 1, 2026 - Mar 31, 2027", last)
+	}
+	if !last.Contains(utcDay(2027, 3, 31)) || last.Contains(utcDay(2027, 4, 1)) {
+		t.Errorf("last fiscal year %v misplaces its boundary days", last)
+	}
+}
@@ -0,0 +1,146 @@
+package calculator
+
+import (
+	"sort"
+	"time"
+
+	"subman/internal/models"
+)
+
+// DefaultForecastMonths is the forecast horizon used when none is chosen
+const DefaultForecastMonths = 12
+
+// ForecastCharge is one upcoming charge of a subscription
+type ForecastCharge struct {
+	SubscriptionID string
+	Name           string
+	Date           time.Time
+	Amount         models.Money // In the subscription's own currency
+}
+
+// ForecastMonth is the cash flow of one calendar month
+type ForecastMonth struct {
+	Month   time.Time // First day of the month
+	Total   models.Money
+	Charges []ForecastCharge // Ordered by date
+}
+
+// Forecast is the month-by-month cash flow of upcoming subscription charges
+// Totals are converted to the base currency of the exchange-rate table
+type Forecast struct {
+	Currency     string
+	Months       []ForecastMonth
+	Total        models.Money
+	MissingRates []string // Currencies with no exchange rate (counted 1:1)
+}
+
+// Average returns the mean monthly total over the forecast
+func (f *Forecast) Average() models.Money {
+	if len(f.Months) == 0 {
+		return f.Total
+	}
+	return f.Total.MulDiv(1, int64(len(f.Months)))
+}
+
+// BiggestMonth returns the month with the highest total; false if nothing is due
+func (f *Forecast) BiggestMonth() (ForecastMonth, bool) {
+	var biggest ForecastMonth
+	found := false
+	for _, month := range f.Months {
+		if len(month.Charges) > 0 && (!found || month.Total.Amount > biggest.Total.Amount) {
+			biggest = month
+			found = true
+		}
+	}
+	return biggest, found
+}
+
+// ForecastCashFlow projects every charge due from today until the end of the
+// month that is months-1 months after now's. Each subscription is stepped
+// through its billing cycle from NextPayment at the price in force on each date,
+// so yearly renewals land in the month they are actually charged. Deleted and
+// cancelled subscriptions, paused dates and skipped payments are left out.
+func ForecastCashFlow(subscriptions []models.Subscription, rates *models.ExchangeRates, now time.Time, months int) *Forecast {
+	if months < 1 {
+		months = DefaultForecastMonths
+	}
+
+	base := rates.BaseCurrency()
+	forecast := &Forecast{
+		Currency: base,
+		Total:    models.Zero(base),
+		Months:   make([]ForecastMonth, months),
+	}
+
+	// Billing dates are UTC midnights, so the months are laid out in UTC from
+	// today's calendar day
+	today := models.CalendarDay(now)
+	first := time.Date(today.Year(), today.Month(), 1, 0, 0, 0, 0, time.UTC)
+	for i := range forecast.Months {
+		forecast.Months[i] = ForecastMonth{Month: first.AddDate(0, i, 0), Total: models.Zero(base)}
+	}
+
+	end := first.AddDate(0, months, 0)
+	missing := make(map[string]bool)
+
+	for _, sub := range subscriptions {
+		if sub.Deleted || sub.IsCancelled() {
+			continue
+		}
+		// Paused before pause history was kept, so PausedAt can't tell when it resumes
+		if sub.Paused && len(sub.Pauses) == 0 {
+			continue
+		}
+
+		interval := sub.Interval()
+		date := sub.NextPayment
+		if date.IsZero() || date.Before(today) {
+			date = sub.BillingStart()
+			for date.Before(today) {
+				date = interval.Next(date)
+			}
+		}
+
+		for ; models.CalendarDay(date).Before(end); date = interval.Next(date) {
+			if sub.PausedAt(date) || sub.IsSkippedPayment(date) {
+				continue
+			}
+
+			day := models.CalendarDay(date)
+			index := (day.Year()-first.Year())*12 + int(day.Month()-first.Month())
+			if index < 0 || index >= len(forecast.Months) {
+				continue
+			}
+
+			price := sub.PriceAt(date)
+			converted, ok := rates.ToBase(price)
+			if !ok {
+				missing[price.CurrencyCode()] = true
+			}
+
+			month := &forecast.Months[index]
+			month.Total = month.Total.Add(converted)
+			month.Charges = append(month.Charges, ForecastCharge{
+				SubscriptionID: sub.ID,
+				Name:           sub.Name,
+				Date:           date,
+				Amount:         price,
+			})
+			forecast.Total = forecast.Total.Add(converted)
+		}
+	}
+
+	for i := range forecast.Months {
+		charges := forecast.Months[i].Charges
+		sort.SliceStable(charges, func(a, b int) bool {
+			return charges[a].Date.Before(charges[b].Date)
+		})
+	}
+
+	for currency := range missing {
+		forecast.MissingRates = append(forecast.MissingRates, currency)
+	}
+	sort.Strings(forecast.MissingRates)
+
+	return forecast
+}
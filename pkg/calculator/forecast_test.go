@@ -0,0 +1,50 @@
+package calculator
+
+import (
+	"testing"
+	"time"
+
+	"subman/internal/models"
+)
+
+func TestForecastCashFlowWestOfUTC(t *testing.T) {
+	// Billing dates are UTC midnights; the month after the horizon begins
+	// before local midnight there
+	newYork := time.FixedZone("EDT", -4*60*60)
+	now := time.Date(2026, 10, 17, 21, 0, 0, 0, newYork) // Already Oct 18 in UTC
+
+	sub := models.Subscription{
+		ID:           "monthly",
+		Name:         "Monthly",
+		Cost:         models.NewMoney(1000, "USD"),
+		BillingCycle: models.Monthly,
+		StartDate:    time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+		NextPayment:  time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC),
+	}
+
+	forecast := ForecastCashFlow([]models.Subscription{sub}, nil, now, 12)
+
+	if len(forecast.Months) != 12 {
+		t.Fatalf("got %d months, want 12", len(forecast.Months))
+	}
+	if got := forecast.Months[0].Month.Format("2006-01"); got != "2026-10" {
+		t.Errorf("first month = %s, want 2026-10", got)
+	}
+	for i, month := range forecast.Months {
+		want := 1
+		if i == 0 {
+			want = 0 // Oct 1 has already been charged
+		}
+		if len(month.Charges) != want {
+			t.Errorf("%s: got %d charges, want %d", month.Month.Format("2006-01"), len(month.Charges), want)
+		}
+		for _, charge := range month.Charges {
+			if charge.Date.Month() != month.Month.Month() {
+				t.Errorf("charge on %s listed in %s", charge.Date.Format("2006-01-02"), month.Month.Format("2006-01"))
+			}
+		}
+	}
+	if forecast.Total != models.NewMoney(11000, "USD") {
+		t.Errorf("total = %v, want 110.00 USD", forecast.Total)
+	}
+}
@@ -0,0 +1,62 @@
+package calculator
+
+import (
+	"time"
+
+	"subman/internal/models"
+)
+
+// PaymentHistorySummary totals a subscription's payment history
+type PaymentHistorySummary struct {
+	Count          int          // Settled payments: charges, refunds and credits
+	Total          models.Money // Lifetime total spent
+	MonthlyAverage models.Money // Total spread over the calendar months since the first payment
+	FirstPayment   time.Time
+}
+
+// SummarizePayments totals payments made up to now; refunds and credits reduce
+// the total and failed or pending payments are ignored
+// Payments in one currency are summed as-is; mixed currencies are converted to
+// the base currency of rates.
+func SummarizePayments(payments []models.Payment, rates *models.ExchangeRates, now time.Time) PaymentHistorySummary {
+	currency := ""
+	mixed := false
+	for _, payment := range payments {
+		if currency == "" {
+			currency = payment.Amount.CurrencyCode()
+		} else if payment.Amount.CurrencyCode() != currency {
+			mixed = true
+		}
+	}
+	if mixed || currency == "" {
+		currency = rates.BaseCurrency()
+	}
+
+	summary := PaymentHistorySummary{
+		Total:          models.Zero(currency),
+		MonthlyAverage: models.Zero(currency),
+	}
+
+	for _, payment := range payments {
+		if payment.PaymentDate.After(now) || !payment.IsSettled() {
+			continue
+		}
+
+		amount := payment.Spent()
+		if mixed {
+			amount, _ = rates.ToBase(amount)
+		}
+		summary.Total = summary.Total.Add(amount)
+		summary.Count++
+		if summary.FirstPayment.IsZero() || payment.PaymentDate.Before(summary.FirstPayment) {
+			summary.FirstPayment = payment.PaymentDate
+		}
+	}
+
+	if summary.Count > 0 {
+		months := (now.Year()-summary.FirstPayment.Year())*12 + int(now.Month()-summary.FirstPayment.Month()) + 1
+		summary.MonthlyAverage = summary.Total.MulDiv(1, int64(months))
+	}
+
+	return summary
+}
@@ -0,0 +1,97 @@
+package calculator
+
+import (
+	"sort"
+	"time"
+
+	"subman/internal/models"
+)
+
+// Debt is an amount one person owes another
+type Debt struct {
+	From   string
+	To     string
+	Amount models.Money
+}
+
+// MonthlySettlement lists who owes whom for the shared charges of one month
+type MonthlySettlement struct {
+	Month time.Time // First day of the month
+	Debts []Debt
+}
+
+// CalculateSettlements works out who owes whom per month from the payments of
+// shared subscriptions made between from and to (inclusive). Every participant
+// owes the payer their share of each charge and is owed their share of each
+// refund; debts in both directions between two people are netted. Amounts are
+// converted to the base currency of rates.
+func CalculateSettlements(subscriptions []models.Subscription, payments []models.Payment, rates *models.ExchangeRates, from, to time.Time) []MonthlySettlement {
+	subs := make(map[string]models.Subscription)
+	for _, sub := range subscriptions {
+		if sub.IsShared() {
+			subs[sub.ID] = sub
+		}
+	}
+
+	type pair struct{ from, to string }
+	months := make(map[time.Time]map[pair]int64)
+	base := rates.BaseCurrency()
+
+	for _, payment := range payments {
+		sub, ok := subs[payment.SubscriptionID]
+		if !ok || !payment.IsSettled() || payment.PaymentDate.Before(from) || payment.PaymentDate.After(to) {
+			continue
+		}
+
+		date := payment.PaymentDate
+		month := time.Date(date.Year(), date.Month(), 1, 0, 0, 0, 0, date.Location())
+		if months[month] == nil {
+			months[month] = make(map[pair]int64)
+		}
+
+		payer := sub.Payer()
+		for _, share := range sub.Shares(payment.Amount) {
+			if share.Name == payer || share.Amount.IsZero() {
+				continue
+			}
+			amount, _ := rates.ToBase(share.Amount)
+
+			// Keep one direction per pair so opposite debts net out
+			if share.Name < payer {
+				months[month][pair{share.Name, payer}] += amount.Amount
+			} else {
+				months[month][pair{payer, share.Name}] -= amount.Amount
+			}
+		}
+	}
+
+	settlements := make([]MonthlySettlement, 0, len(months))
+	for month, balances := range months {
+		settlement := MonthlySettlement{Month: month}
+		for p, amount := range balances {
+			switch {
+			case amount > 0:
+				settlement.Debts = append(settlement.Debts, Debt{From: p.from, To: p.to, Amount: models.NewMoney(amount, base)})
+			case amount < 0:
+				settlement.Debts = append(settlement.Debts, Debt{From: p.to, To: p.from, Amount: models.NewMoney(-amount, base)})
+			}
+		}
+		if len(settlement.Debts) == 0 {
+			continue
+		}
+
+		sort.Slice(settlement.Debts, func(i, j int) bool {
+			if settlement.Debts[i].From != settlement.Debts[j].From {
+				return settlement.Debts[i].From < settlement.Debts[j].From
+			}
+			return settlement.Debts[i].To < settlement.Debts[j].To
+		})
+		settlements = append(settlements, settlement)
+	}
+
+	sort.Slice(settlements, func(i, j int) bool {
+		return settlements[i].Month.Before(settlements[j].Month)
+	})
+
+	return settlements
+}
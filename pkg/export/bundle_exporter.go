@@ -2,6 +2,7 @@ package export
 
 import (
 	"archive/zip"
+	"bytes"
 	"encoding/json"
 	"io"
 	"os"
@@ -9,6 +10,7 @@ import (
 	"strings"
 
 	"subman/internal/models"
+	"subman/pkg/vault"
 )
 
 type BundleExporter struct {
@@ -46,6 +48,13 @@ func (e *BundleExporter) ExportBundle(list *models.SubscriptionList, writer io.W
 		}
 	}
 
+	// Category icons chosen by the user are user-supplied images too
+	for _, cat := range list.Categories {
+		if cat.CustomIcon {
+			imageFiles["default_"+string(cat.ID)+".png"] = true
+		}
+	}
+
 	// 3. Add each image file to the ZIP under images/ folder
 	for filename := range imageFiles {
 		imagePath := filepath.Join(e.imagesDir, filename)
@@ -78,3 +87,20 @@ func (e *BundleExporter) ExportBundle(list *models.SubscriptionList, writer io.W
 
 	return nil
 }
+
+// ExportEncryptedBundle creates the same ZIP archive as ExportBundle, encrypted
+// with passphrase as a whole so neither the data nor the images are readable
+func (e *BundleExporter) ExportEncryptedBundle(list *models.SubscriptionList, writer io.Writer, passphrase string) error {
+	var archive bytes.Buffer
+	if err := e.ExportBundle(list, &archive); err != nil {
+		return err
+	}
+
+	sealed, err := vault.Seal(archive.Bytes(), passphrase)
+	if err != nil {
+		return err
+	}
+
+	_, err = writer.Write(sealed)
+	return err
+}
@@ -4,6 +4,8 @@ import (
 	"encoding/csv"
 	"io"
 	"strconv"
+	"strings"
+	"time"
 
 	"subman/internal/models"
 )
@@ -19,20 +21,26 @@ func (e *CSVExporter) Export(subscriptions []models.Subscription, writer io.Writ
 	defer csvWriter.Flush()
 
 	// Write header
-	header := []string{"Name", "Cost", "Billing Cycle", "Next Payment", "Start Date", "Category", "Notes"}
+	header := []string{"Name", "Cost", "Currency", "Billing Cycle", "Interval Count", "Interval Unit", "Next Payment", "Start Date", "Category", "Tags", "Status", "Notes"}
 	if err := csvWriter.Write(header); err != nil {
 		return err
 	}
 
 	// Write data
 	for _, sub := range subscriptions {
+		interval := sub.Interval()
 		record := []string{
 			sub.Name,
-			strconv.FormatFloat(sub.Cost, 'f', 2, 64),
+			sub.Cost.Decimal(),
+			sub.Cost.CurrencyCode(),
 			string(sub.BillingCycle),
+			strconv.Itoa(interval.Count),
+			string(interval.Unit),
 			sub.NextPayment.Format("2006-01-02"),
 			sub.StartDate.Format("2006-01-02"),
 			string(sub.Category),
+			strings.Join(sub.Tags, ";"),
+			string(sub.Status(time.Now())),
 			sub.Notes,
 		}
 		if err := csvWriter.Write(record); err != nil {
@@ -2,7 +2,8 @@ package importer
 
 import (
 	"archive/zip"
-	"encoding/json"
+	"bytes"
+	"errors"
 	"fmt"
 	"io"
 	"os"
@@ -10,6 +11,8 @@ import (
 	"strings"
 
 	"subman/internal/models"
+	"subman/internal/storage"
+	"subman/pkg/vault"
 )
 
 type ImportMode string
@@ -19,8 +22,15 @@ const (
 	ImportModeMerge   ImportMode = "merge"   // Merge with existing data
 )
 
+// EncryptedBundleExt is the file extension of encrypted bundles
+const EncryptedBundleExt = ".subman"
+
+// ErrPassphraseRequired is returned when opening an encrypted bundle without a passphrase
+var ErrPassphraseRequired = errors.New("bundle is encrypted; a passphrase is required")
+
 type BundleImporter struct {
-	imagesDir string
+	imagesDir  string
+	passphrase string // For encrypted bundles
 }
 
 func NewBundleImporter(imagesDir string) *BundleImporter {
@@ -29,92 +39,134 @@ func NewBundleImporter(imagesDir string) *BundleImporter {
 	}
 }
 
-// ImportBundle extracts and imports a bundle ZIP file
+// SetPassphrase sets the passphrase encrypted bundles are opened with
+func (i *BundleImporter) SetPassphrase(passphrase string) {
+	i.passphrase = passphrase
+}
+
+// IsEncryptedBundle reports whether the file at path is an encrypted bundle
+func IsEncryptedBundle(path string) (bool, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return false, err
+	}
+	defer file.Close()
+
+	header := make([]byte, 16)
+	n, err := io.ReadFull(file, header)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return false, err
+	}
+	return vault.IsSealed(header[:n]), nil
+}
+
+// openBundle reads the bundle ZIP at path, decrypting it first if it is encrypted
+func (i *BundleImporter) openBundle(path string) (*zip.Reader, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if vault.IsSealed(data) {
+		if i.passphrase == "" {
+			return nil, ErrPassphraseRequired
+		}
+		if data, _, err = vault.Open(data, i.passphrase); err != nil {
+			return nil, err
+		}
+	}
+
+	return zip.NewReader(bytes.NewReader(data), int64(len(data)))
+}
+
+// ImportBundle extracts and imports a bundle ZIP file, encrypted or not
+// Bundles exported by older versions are upgraded like a data file; bundles
+// from a newer version are refused before anything is extracted.
 func (i *BundleImporter) ImportBundle(zipPath string, mode ImportMode) (*models.SubscriptionList, error) {
 	// Open the ZIP file
-	zipReader, err := zip.OpenReader(zipPath)
+	zipReader, err := i.openBundle(zipPath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open bundle: %w", err)
 	}
-	defer zipReader.Close()
 
-	var subscriptionList *models.SubscriptionList
+	subscriptionList, err := readSubscriptions(zipReader)
+	if err != nil {
+		return nil, err
+	}
 
-	// Extract files from ZIP
+	// Extract image files
 	for _, file := range zipReader.File {
-		if file.Name == "subscriptions.json" {
-			// Read and parse subscriptions.json
-			rc, err := file.Open()
-			if err != nil {
-				return nil, fmt.Errorf("failed to read subscriptions.json: %w", err)
-			}
-
-			decoder := json.NewDecoder(rc)
-			var list models.SubscriptionList
-			if err := decoder.Decode(&list); err != nil {
-				rc.Close()
-				return nil, fmt.Errorf("failed to parse subscriptions.json: %w", err)
-			}
-			rc.Close()
-
-			subscriptionList = &list
-
-		} else if strings.HasPrefix(file.Name, "images/") {
-			// Extract image files
-			imageName := filepath.Base(file.Name)
-			if imageName == "" || imageName == "." {
-				continue
-			}
+		if !strings.HasPrefix(file.Name, "images/") {
+			continue
+		}
 
-			destPath := filepath.Join(i.imagesDir, imageName)
+		imageName := filepath.Base(file.Name)
+		if imageName == "" || imageName == "." {
+			continue
+		}
 
-			rc, err := file.Open()
-			if err != nil {
-				return nil, fmt.Errorf("failed to read image %s: %w", imageName, err)
-			}
+		destPath := filepath.Join(i.imagesDir, imageName)
 
-			destFile, err := os.Create(destPath)
-			if err != nil {
-				rc.Close()
-				return nil, fmt.Errorf("failed to create image %s: %w", imageName, err)
-			}
+		rc, err := file.Open()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read image %s: %w", imageName, err)
+		}
 
-			_, err = io.Copy(destFile, rc)
-			destFile.Close()
+		destFile, err := os.Create(destPath)
+		if err != nil {
 			rc.Close()
+			return nil, fmt.Errorf("failed to create image %s: %w", imageName, err)
+		}
+
+		_, err = io.Copy(destFile, rc)
+		destFile.Close()
+		rc.Close()
 
-			if err != nil {
-				return nil, fmt.Errorf("failed to extract image %s: %w", imageName, err)
-			}
+		if err != nil {
+			return nil, fmt.Errorf("failed to extract image %s: %w", imageName, err)
 		}
 	}
 
-	if subscriptionList == nil {
-		return nil, fmt.Errorf("bundle does not contain subscriptions.json")
+	return subscriptionList, nil
+}
+
+// readSubscriptions reads the bundle's subscriptions.json, migrated to the current format
+func readSubscriptions(zipReader *zip.Reader) (*models.SubscriptionList, error) {
+	for _, file := range zipReader.File {
+		if file.Name != "subscriptions.json" {
+			continue
+		}
+
+		rc, err := file.Open()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read subscriptions.json: %w", err)
+		}
+		data, err := io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read subscriptions.json: %w", err)
+		}
+
+		list, err := storage.DecodeList(data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse subscriptions.json: %w", err)
+		}
+		return list, nil
 	}
 
-	return subscriptionList, nil
+	return nil, fmt.Errorf("bundle does not contain subscriptions.json")
 }
 
 // ValidateBundle checks if a ZIP file is a valid subscription bundle
 func (i *BundleImporter) ValidateBundle(zipPath string) error {
-	zipReader, err := zip.OpenReader(zipPath)
+	zipReader, err := i.openBundle(zipPath)
+	if errors.Is(err, ErrPassphraseRequired) || errors.Is(err, vault.ErrWrongPassphrase) {
+		return err
+	}
 	if err != nil {
 		return fmt.Errorf("not a valid ZIP file: %w", err)
 	}
-	defer zipReader.Close()
-
-	hasSubscriptions := false
-	for _, file := range zipReader.File {
-		if file.Name == "subscriptions.json" {
-			hasSubscriptions = true
-			break
-		}
-	}
-
-	if !hasSubscriptions {
-		return fmt.Errorf("bundle does not contain subscriptions.json")
-	}
 
-	return nil
+	_, err = readSubscriptions(zipReader)
+	return err
 }
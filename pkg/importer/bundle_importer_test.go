@@ -0,0 +1,86 @@
+package importer
+
+import (
+	"archive/zip"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"subman/internal/models"
+	"subman/internal/storage"
+)
+
+// writeBundle writes a bundle ZIP holding the given subscriptions.json and one image
+func writeBundle(t *testing.T, subscriptions string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "bundle.zip")
+	file, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	zipWriter := zip.NewWriter(file)
+	entries := map[string]string{
+		"subscriptions.json": subscriptions,
+		"images/logo.png":    "png",
+	}
+	for name, content := range entries {
+		w, err := zipWriter.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := zipWriter.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestImportBundleMigratesOldVersions(t *testing.T) {
+	bundle := writeBundle(t, `{
+		"subscriptions": [{"id": "1", "name": "Netflix", "cost": 15.99, "billing_cycle": "monthly"}],
+		"payments": [{"id": "p1", "subscription_id": "1", "amount": 15.99, "notes": "Auto-generated"}],
+		"version": "1.0"
+	}`)
+
+	imagesDir := t.TempDir()
+	list, err := NewBundleImporter(imagesDir).ImportBundle(bundle, ImportModeReplace)
+	if err != nil {
+		t.Fatalf("ImportBundle: %v", err)
+	}
+
+	if got := list.Subscriptions[0].Cost; got != models.NewMoney(1599, "USD") {
+		t.Errorf("cost = %v, want 15.99 USD", got)
+	}
+	payment := list.Payments[0]
+	if payment.Amount != models.NewMoney(1599, "USD") || payment.Type != models.PaymentCharged || payment.Source != models.SourceGenerated {
+		t.Errorf("payment = %+v, want a generated 15.99 USD charge", payment)
+	}
+	if _, err := os.Stat(filepath.Join(imagesDir, "logo.png")); err != nil {
+		t.Errorf("image not extracted: %v", err)
+	}
+}
+
+func TestImportBundleRefusesNewerVersion(t *testing.T) {
+	bundle := writeBundle(t, `{"subscriptions": [], "payments": [], "version": "9.0"}`)
+
+	imagesDir := t.TempDir()
+	importer := NewBundleImporter(imagesDir)
+	if err := importer.ValidateBundle(bundle); !errors.Is(err, storage.ErrNewerVersion) {
+		t.Errorf("ValidateBundle: error = %v, want ErrNewerVersion", err)
+	}
+	if _, err := importer.ImportBundle(bundle, ImportModeReplace); !errors.Is(err, storage.ErrNewerVersion) {
+		t.Fatalf("ImportBundle: error = %v, want ErrNewerVersion", err)
+	}
+
+	// Nothing is extracted from a bundle that isn't imported
+	if entries, _ := os.ReadDir(imagesDir); len(entries) != 0 {
+		t.Errorf("extracted %d images from a refused bundle", len(entries))
+	}
+}
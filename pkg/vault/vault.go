@@ -0,0 +1,201 @@
+// Package vault encrypts data with a passphrase: the key is derived with
+// argon2id and the data sealed with AES-256-GCM, so tampering is detected
+package vault
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/binary"
+	"errors"
+
+	"golang.org/x/crypto/argon2"
+)
+
+// magic starts every sealed file; the trailing digit is the format version
+const magic = "SUBMANV1"
+
+const (
+	saltSize  = 16
+	keySize   = 32
+	nonceSize = 12
+
+	// headerSize is magic, time, memory, threads, salt and nonce
+	headerSize = len(magic) + 4 + 4 + 1 + saltSize + nonceSize
+)
+
+var (
+	ErrNotSealed       = errors.New("data is not encrypted")
+	ErrWrongPassphrase = errors.New("wrong passphrase, or the encrypted data is damaged")
+	ErrEmptyPassphrase = errors.New("passphrase cannot be empty")
+)
+
+// Params are the argon2id cost settings
+type Params struct {
+	Time    uint32 // Passes over the memory
+	Memory  uint32 // KiB
+	Threads uint8
+}
+
+// DefaultParams take a fraction of a second and 64 MiB to derive a key
+var DefaultParams = Params{
+	Time:    3,
+	Memory:  64 * 1024,
+	Threads: 4,
+}
+
+// Key is a key derived from a passphrase
+// Deriving is deliberately slow, so a Key is kept to seal many times with one
+// derivation; every Seal still uses a fresh nonce.
+type Key struct {
+	params Params
+	salt   []byte
+	key    []byte
+}
+
+// NewKey derives a key from passphrase with a fresh random salt
+func NewKey(passphrase string) (*Key, error) {
+	if passphrase == "" {
+		return nil, ErrEmptyPassphrase
+	}
+
+	salt := make([]byte, saltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	return deriveKey(passphrase, salt, DefaultParams), nil
+}
+
+func deriveKey(passphrase string, salt []byte, params Params) *Key {
+	return &Key{
+		params: params,
+		salt:   salt,
+		key:    argon2.IDKey([]byte(passphrase), salt, params.Time, params.Memory, params.Threads, keySize),
+	}
+}
+
+// IsSealed reports whether data was produced by Seal
+func IsSealed(data []byte) bool {
+	return bytes.HasPrefix(data, []byte(magic))
+}
+
+// Seal encrypts plaintext with a key derived from passphrase
+func Seal(plaintext []byte, passphrase string) ([]byte, error) {
+	key, err := NewKey(passphrase)
+	if err != nil {
+		return nil, err
+	}
+	return key.Seal(plaintext)
+}
+
+// Open decrypts data sealed with passphrase and returns the key it was sealed
+// with, to seal again without deriving another
+func Open(data []byte, passphrase string) ([]byte, *Key, error) {
+	if passphrase == "" {
+		return nil, nil, ErrEmptyPassphrase
+	}
+
+	h, err := parseHeader(data)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	key := deriveKey(passphrase, h.salt, h.params)
+	plaintext, err := key.open(h, data)
+	if err != nil {
+		return nil, nil, err
+	}
+	return plaintext, key, nil
+}
+
+// Seal encrypts plaintext; the header with the salt and cost settings is
+// authenticated along with it
+func (k *Key) Seal(plaintext []byte) ([]byte, error) {
+	nonce := make([]byte, nonceSize)
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	header := make([]byte, 0, headerSize)
+	header = append(header, magic...)
+	header = binary.BigEndian.AppendUint32(header, k.params.Time)
+	header = binary.BigEndian.AppendUint32(header, k.params.Memory)
+	header = append(header, k.params.Threads)
+	header = append(header, k.salt...)
+	header = append(header, nonce...)
+
+	aead, err := k.aead()
+	if err != nil {
+		return nil, err
+	}
+	return aead.Seal(header, nonce, plaintext, header), nil
+}
+
+// Open decrypts data sealed with this key
+// Data sealed with the same passphrase but another salt needs the package-level Open.
+func (k *Key) Open(data []byte) ([]byte, error) {
+	h, err := parseHeader(data)
+	if err != nil {
+		return nil, err
+	}
+	if h.params != k.params || subtle.ConstantTimeCompare(h.salt, k.salt) != 1 {
+		return nil, ErrWrongPassphrase
+	}
+	return k.open(h, data)
+}
+
+func (k *Key) open(h header, data []byte) ([]byte, error) {
+	aead, err := k.aead()
+	if err != nil {
+		return nil, err
+	}
+
+	plaintext, err := aead.Open(nil, h.nonce, data[headerSize:], data[:headerSize])
+	if err != nil {
+		return nil, ErrWrongPassphrase
+	}
+	return plaintext, nil
+}
+
+func (k *Key) aead() (cipher.AEAD, error) {
+	block, err := aes.NewCipher(k.key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// header is the unencrypted start of sealed data
+type header struct {
+	params Params
+	salt   []byte
+	nonce  []byte
+}
+
+func parseHeader(data []byte) (header, error) {
+	if !IsSealed(data) {
+		return header{}, ErrNotSealed
+	}
+	if len(data) < headerSize {
+		return header{}, ErrWrongPassphrase
+	}
+
+	rest := data[len(magic):headerSize]
+	h := header{
+		params: Params{
+			Time:    binary.BigEndian.Uint32(rest[0:4]),
+			Memory:  binary.BigEndian.Uint32(rest[4:8]),
+			Threads: rest[8],
+		},
+		salt:  rest[9 : 9+saltSize],
+		nonce: rest[9+saltSize:],
+	}
+
+	// Refuse settings that would make deriving the key hang or run out of memory
+	if h.params.Time == 0 || h.params.Time > 16 || h.params.Memory < 8 || h.params.Memory > 1024*1024 || h.params.Threads == 0 {
+		return header{}, ErrWrongPassphrase
+	}
+	return h, nil
+}
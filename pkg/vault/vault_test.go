@@ -0,0 +1,99 @@
+package vault
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+// Cheap settings keep the tests fast; the format is the same
+func init() {
+	DefaultParams = Params{Time: 1, Memory: 8 * 1024, Threads: 1}
+}
+
+func TestSealOpen(t *testing.T) {
+	plaintext := []byte(`{"subscriptions": []}`)
+
+	sealed, err := Seal(plaintext, "correct horse")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !IsSealed(sealed) {
+		t.Error("sealed data is not recognized as sealed")
+	}
+	if bytes.Contains(sealed, plaintext) {
+		t.Error("sealed data contains the plaintext")
+	}
+
+	opened, key, err := Open(sealed, "correct horse")
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	if !bytes.Equal(opened, plaintext) {
+		t.Errorf("opened %q, want %q", opened, plaintext)
+	}
+
+	// The key from Open seals again without another derivation
+	resealed, err := key.Seal(plaintext)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Equal(resealed, sealed) {
+		t.Error("sealing twice gave the same output; the nonce was reused")
+	}
+	if opened, err := key.Open(resealed); err != nil || !bytes.Equal(opened, plaintext) {
+		t.Errorf("Key.Open = %q, %v", opened, err)
+	}
+}
+
+func TestOpenWrongPassphrase(t *testing.T) {
+	sealed, err := Seal([]byte("secret"), "correct horse")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, _, err := Open(sealed, "battery staple"); !errors.Is(err, ErrWrongPassphrase) {
+		t.Errorf("Open with the wrong passphrase: error = %v, want ErrWrongPassphrase", err)
+	}
+	if _, _, err := Open(sealed, ""); !errors.Is(err, ErrEmptyPassphrase) {
+		t.Errorf("Open with no passphrase: error = %v, want ErrEmptyPassphrase", err)
+	}
+
+	// A key for the same passphrase with another salt doesn't fit either
+	other, err := NewKey("correct horse")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := other.Open(sealed); !errors.Is(err, ErrWrongPassphrase) {
+		t.Errorf("Key.Open with another salt: error = %v, want ErrWrongPassphrase", err)
+	}
+}
+
+func TestOpenTampered(t *testing.T) {
+	sealed, err := Seal([]byte("secret"), "correct horse")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		tamper func(data []byte) []byte
+	}{
+		{"ciphertext", func(data []byte) []byte { data[len(data)-1] ^= 1; return data }},
+		{"salt", func(data []byte) []byte { data[len(magic)+9] ^= 1; return data }},
+		{"nonce", func(data []byte) []byte { data[headerSize-1] ^= 1; return data }},
+		{"truncated", func(data []byte) []byte { return data[:headerSize-1] }},
+		{"cost settings", func(data []byte) []byte { data[len(magic)+3] = 0xff; return data }},
+	}
+
+	for _, tt := range tests {
+		data := tt.tamper(bytes.Clone(sealed))
+		if _, _, err := Open(data, "correct horse"); !errors.Is(err, ErrWrongPassphrase) {
+			t.Errorf("%s: error = %v, want ErrWrongPassphrase", tt.name, err)
+		}
+	}
+
+	if _, _, err := Open([]byte(`{"subscriptions": []}`), "correct horse"); !errors.Is(err, ErrNotSealed) {
+		t.Errorf("plain data: error = %v, want ErrNotSealed", err)
+	}
+}